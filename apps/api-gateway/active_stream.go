@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activeJobSubscribers fans out every JobUpdate passing through
+// WebSocketHub.BroadcastJobUpdate to SSE clients streaming
+// GET /api/jobs/active/stream, regardless of which job or repo the update
+// belongs to — unlike jobClients/repoClients, which only deliver to clients
+// subscribed to that specific job or repo.
+var (
+	activeJobSubscribersMu sync.Mutex
+	activeJobSubscribers   = make(map[chan JobUpdate]bool)
+)
+
+// fanOutActiveJobUpdate delivers update to every client streaming
+// /api/jobs/active/stream.
+func fanOutActiveJobUpdate(update JobUpdate) {
+	activeJobSubscribersMu.Lock()
+	defer activeJobSubscribersMu.Unlock()
+	for ch := range activeJobSubscribers {
+		select {
+		case ch <- update:
+		default:
+			// Slow subscriber; drop the update rather than block the broadcaster.
+		}
+	}
+}
+
+// subscribeActiveJobUpdates registers a channel to receive every job's
+// status transitions; the caller must call the returned func to unregister.
+func subscribeActiveJobUpdates() (chan JobUpdate, func()) {
+	ch := make(chan JobUpdate, 64)
+
+	activeJobSubscribersMu.Lock()
+	activeJobSubscribers[ch] = true
+	activeJobSubscribersMu.Unlock()
+
+	unsubscribe := func() {
+		activeJobSubscribersMu.Lock()
+		delete(activeJobSubscribers, ch)
+		activeJobSubscribersMu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// streamActiveJobUpdates handles GET /api/jobs/active/stream: an SSE
+// endpoint that replays a snapshot of jobTracker's currently active jobs and
+// then streams every subsequent job status transition as it's broadcast.
+func streamActiveJobUpdates(c *gin.Context) {
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming unsupported"})
+		return
+	}
+
+	ch, unsubscribe := subscribeActiveJobUpdates()
+	defer unsubscribe()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	for _, active := range jobTracker.Actives() {
+		writeActiveStreamSSEEvent(c.Writer, JobUpdate{
+			Type:      "snapshot",
+			JobID:     active.JobID,
+			RepoID:    generateRepoID(active.RepoURL),
+			Status:    "active",
+			Timestamp: active.StartedAt,
+		})
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case update, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeActiveStreamSSEEvent(c.Writer, update)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeActiveStreamSSEEvent(w io.Writer, update JobUpdate) {
+	data, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal active job update for stream: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+}
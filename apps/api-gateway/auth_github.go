@@ -0,0 +1,401 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	githuboauth "github.com/Arch-Mind/backend/internal/auth/github"
+	"github.com/gin-gonic/gin"
+)
+
+// githubOAuthStateCookie carries the CSRF state value set by
+// githubOAuthLogin across the redirect to GitHub and back in a cookie
+// rather than in server-side session storage, since this gateway has no
+// session store.
+const githubOAuthStateCookie = "github_oauth_state"
+
+// ensureGitHubOAuthSchema creates the table backing linked GitHub accounts.
+// Tokens are stored AES-GCM encrypted at rest under GITHUB_OAUTH_ENCRYPTION_KEY
+// rather than in plaintext the way github_app_installations.private_key_pem
+// is, since a leaked row here is directly usable against a user's GitHub
+// account rather than scoped to one App installation.
+func ensureGitHubOAuthSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS github_oauth_tokens (
+			user_id TEXT PRIMARY KEY,
+			access_token_encrypted TEXT NOT NULL,
+			refresh_token_encrypted TEXT,
+			scope VARCHAR(255),
+			expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+	`)
+	return err
+}
+
+// githubOAuthConfig builds the OAuth config from env, matching
+// resolveGitHubToken's "read straight from the environment" convention.
+func githubOAuthConfig() githuboauth.Config {
+	return githuboauth.Config{
+		ClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+		ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+		RedirectURL:  getEnv("GITHUB_OAUTH_REDIRECT_URL", ""),
+	}
+}
+
+// githubOAuthLogin handles GET /auth/github/login?user_id=...: mints a CSRF
+// state that binds the caller's user_id (see buildOAuthState), stashes it in
+// a short-lived cookie, and redirects to GitHub's authorize page. GitHub's
+// redirect back to githubOAuthCallback only ever carries code/state, so
+// user_id must travel inside state itself rather than as a second query
+// param the callback would otherwise have to trust blindly.
+func githubOAuthLogin(c *gin.Context) {
+	cfg := githubOAuthConfig()
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "GitHub OAuth is not configured"})
+		return
+	}
+
+	userID := c.Query("user_id")
+	if userID == "" {
+		validationError(c, "user_id", "user_id is required to link a GitHub account.")
+		return
+	}
+
+	state, err := buildOAuthState(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start GitHub OAuth flow"})
+		return
+	}
+
+	c.SetCookie(githubOAuthStateCookie, state, 600, "/", "", true, true)
+	c.Redirect(http.StatusFound, cfg.AuthCodeURL(state))
+}
+
+// githubOAuthCallback handles GET /auth/github/callback: validates the
+// round-tripped state and recovers the user_id buildOAuthState bound into it
+// (rather than trusting a bare user_id query param, which anyone completing
+// their own OAuth round trip could set to any value), then exchanges the
+// code for a token and persists it encrypted against that user_id.
+func githubOAuthCallback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState, err := c.Cookie(githubOAuthStateCookie)
+	if err != nil || state == "" || state != cookieState {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing OAuth state"})
+		return
+	}
+	c.SetCookie(githubOAuthStateCookie, "", -1, "/", "", true, true)
+
+	userID, ok := oauthStateUserID(state)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing OAuth state"})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		validationError(c, "code", "code is required.")
+		return
+	}
+
+	cfg := githubOAuthConfig()
+	token, err := cfg.Exchange(c.Request.Context(), code)
+	if err != nil {
+		log.Printf("⚠️  GitHub OAuth exchange failed for user %s: %v", userID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to exchange GitHub OAuth code"})
+		return
+	}
+
+	if err := saveGitHubOAuthToken(userID, *token); err != nil {
+		log.Printf("⚠️  Failed to persist GitHub OAuth token for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link GitHub account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"linked": true, "scope": token.Scope})
+}
+
+// saveGitHubOAuthToken upserts the encrypted token for userID.
+func saveGitHubOAuthToken(userID string, token githuboauth.Token) error {
+	accessEncrypted, err := encryptGitHubToken(token.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+
+	var refreshEncrypted sql.NullString
+	if token.RefreshToken != "" {
+		enc, err := encryptGitHubToken(token.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt refresh token: %w", err)
+		}
+		refreshEncrypted = sql.NullString{String: enc, Valid: true}
+	}
+
+	var expiresAt sql.NullTime
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: token.ExpiresAt, Valid: true}
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO github_oauth_tokens (user_id, access_token_encrypted, refresh_token_encrypted, scope, expires_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		ON CONFLICT (user_id) DO UPDATE SET
+			access_token_encrypted = EXCLUDED.access_token_encrypted,
+			refresh_token_encrypted = EXCLUDED.refresh_token_encrypted,
+			scope = EXCLUDED.scope,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = now()
+	`, userID, accessEncrypted, refreshEncrypted, token.Scope, expiresAt)
+	return err
+}
+
+// loadGitHubOAuthToken returns the decrypted token linked to userID, or
+// sql.ErrNoRows if no account is linked.
+func loadGitHubOAuthToken(userID string) (*githuboauth.Token, error) {
+	var accessEncrypted string
+	var refreshEncrypted sql.NullString
+	var scope sql.NullString
+	var expiresAt sql.NullTime
+
+	err := db.QueryRow(`
+		SELECT access_token_encrypted, refresh_token_encrypted, scope, expires_at
+		FROM github_oauth_tokens WHERE user_id = $1
+	`, userID).Scan(&accessEncrypted, &refreshEncrypted, &scope, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := decryptGitHubToken(accessEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+
+	token := &githuboauth.Token{AccessToken: accessToken, Scope: scope.String}
+	if refreshEncrypted.Valid {
+		refreshToken, err := decryptGitHubToken(refreshEncrypted.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt refresh token: %w", err)
+		}
+		token.RefreshToken = refreshToken
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = expiresAt.Time
+	}
+	return token, nil
+}
+
+// attachGitHubTokenSource is Gin middleware that, when the request carries a
+// linked user_id, attaches a *github.TokenSource to the context under
+// githubTokenSourceKey for handlers (like analyzeRepository's clone step) to
+// use. It never rejects the request itself — analyzeRepository decides
+// whether a missing token source is fatal for a given repo.
+func attachGitHubTokenSource(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.Next()
+		return
+	}
+
+	token, err := loadGitHubOAuthToken(userID)
+	if err == sql.ErrNoRows {
+		c.Next()
+		return
+	} else if err != nil {
+		log.Printf("⚠️  Failed to load GitHub OAuth token for user %s: %v", userID, err)
+		c.Next()
+		return
+	}
+
+	c.Set(githubTokenSourceKey, &githuboauth.TokenSource{
+		Config: githubOAuthConfig(),
+		Token:  *token,
+		Save:   func(t githuboauth.Token) error { return saveGitHubOAuthToken(userID, t) },
+	})
+	c.Next()
+}
+
+// githubTokenSourceKey is the gin.Context key attachGitHubTokenSource stores
+// the *github.TokenSource under.
+const githubTokenSourceKey = "githubTokenSource"
+
+// githubTokenSourceFromContext returns the linked token source for the
+// current request, if attachGitHubTokenSource found one.
+func githubTokenSourceFromContext(c *gin.Context) (*githuboauth.TokenSource, bool) {
+	v, ok := c.Get(githubTokenSourceKey)
+	if !ok {
+		return nil, false
+	}
+	ts, ok := v.(*githuboauth.TokenSource)
+	return ts, ok
+}
+
+// jobGitHubTokenTTL bounds how long a job's resolved GitHub OAuth token is
+// held in Redis, matching the window a worker could plausibly still be
+// cloning/re-cloning it in; comfortably longer than jobCancelKeyTTL since a
+// slow clone of a large private repo shouldn't lose its credential mid-job.
+const jobGitHubTokenTTL = 2 * time.Hour
+
+// jobGitHubTokenKey is the Redis key a worker fetches a private job's
+// resolved GitHub OAuth token from, following jobCancelKey's
+// "job:<id>:<purpose>" convention. Deliberately out-of-band from
+// analysis_jobs.options: that column is echoed back verbatim by the
+// unauthenticated GET /api/v1/jobs and GET /api/v1/jobs/:id endpoints, so a
+// plaintext token never belongs there.
+func jobGitHubTokenKey(jobID string) string {
+	return fmt.Sprintf("job:%s:github_token", jobID)
+}
+
+// storeJobGitHubToken stashes a resolved GitHub OAuth token for jobID in
+// Redis so a worker cloning a private repo can fetch it out-of-band; see
+// jobGitHubTokenKey.
+func storeJobGitHubToken(jobID, token string) error {
+	return redisClient.Set(ctx, jobGitHubTokenKey(jobID), token, jobGitHubTokenTTL).Err()
+}
+
+// redactJobOptions strips any GitHub token out of job.Options before a job
+// row is marshaled into an API response. Defense in depth alongside
+// storeJobGitHubToken keeping the token out of Options in the first place:
+// this also covers a job whose options were set by some other path (e.g. a
+// direct analysis_jobs write, or a future caller re-introducing the key).
+func redactJobOptions(job *AnalysisJob) {
+	if job == nil || job.Options == nil {
+		return
+	}
+	delete(job.Options, "github_token")
+}
+
+// githubOAuthStateSecret resolves the HMAC key buildOAuthState/oauthStateUserID
+// sign the state's user_id binding with, falling back to the OAuth client
+// secret (already confidential, and already required for OAuth to be
+// configured at all) when GITHUB_OAUTH_STATE_SECRET isn't set separately.
+func githubOAuthStateSecret() string {
+	if secret := getEnv("GITHUB_OAUTH_STATE_SECRET", ""); secret != "" {
+		return secret
+	}
+	return getEnv("GITHUB_CLIENT_SECRET", "")
+}
+
+// buildOAuthState mints a CSRF state value for the OAuth authorize redirect
+// that also binds userID to this login attempt: a random nonce (so the
+// value is unguessable and unique per attempt) plus an HMAC over
+// nonce+userID, so oauthStateUserID can later recover userID from the state
+// itself instead of the callback trusting a separate, attacker-settable
+// query parameter.
+func buildOAuthState(userID string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	nonceEncoded := base64.RawURLEncoding.EncodeToString(nonce)
+	userIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(userID))
+	sig := oauthStateSignature(nonceEncoded, userIDEncoded)
+	return nonceEncoded + "." + userIDEncoded + "." + sig, nil
+}
+
+// oauthStateUserID recovers and authenticates the user_id buildOAuthState
+// bound into state, reporting ok=false if state is malformed or its HMAC
+// doesn't match (tampered, or minted without knowing githubOAuthStateSecret).
+func oauthStateUserID(state string) (userID string, ok bool) {
+	parts := strings.SplitN(state, ".", 3)
+	if len(parts) != 3 {
+		return "", false
+	}
+	nonceEncoded, userIDEncoded, sig := parts[0], parts[1], parts[2]
+
+	if !hmac.Equal([]byte(sig), []byte(oauthStateSignature(nonceEncoded, userIDEncoded))) {
+		return "", false
+	}
+	userIDBytes, err := base64.RawURLEncoding.DecodeString(userIDEncoded)
+	if err != nil {
+		return "", false
+	}
+	return string(userIDBytes), true
+}
+
+// oauthStateSignature computes the HMAC-SHA256 binding a state's nonce to
+// its encoded user_id, shared by buildOAuthState and oauthStateUserID.
+func oauthStateSignature(nonceEncoded, userIDEncoded string) string {
+	mac := hmac.New(sha256.New, []byte(githubOAuthStateSecret()))
+	mac.Write([]byte(nonceEncoded + "." + userIDEncoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// gitHubOAuthEncryptionKey resolves the AES-256-GCM key from
+// GITHUB_OAUTH_ENCRYPTION_KEY, which must be 64 hex characters (32 bytes).
+func gitHubOAuthEncryptionKey() ([]byte, error) {
+	hexKey := getEnv("GITHUB_OAUTH_ENCRYPTION_KEY", "")
+	if hexKey == "" {
+		return nil, fmt.Errorf("GITHUB_OAUTH_ENCRYPTION_KEY is not set")
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("GITHUB_OAUTH_ENCRYPTION_KEY must be 64 hex characters (32 bytes)")
+	}
+	return key, nil
+}
+
+// encryptGitHubToken seals plaintext with AES-256-GCM, returning
+// base64(nonce || ciphertext).
+func encryptGitHubToken(plaintext string) (string, error) {
+	key, err := gitHubOAuthEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptGitHubToken reverses encryptGitHubToken.
+func decryptGitHubToken(encoded string) (string, error) {
+	key, err := gitHubOAuthEncryptionKey()
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecryptGitHubToken_RoundTrips(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+
+	encrypted, err := encryptGitHubToken("gho_supersecret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "gho_supersecret", encrypted)
+
+	decrypted, err := decryptGitHubToken(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "gho_supersecret", decrypted)
+}
+
+func TestEncryptGitHubToken_FailsWithoutConfiguredKey(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_ENCRYPTION_KEY", "")
+	_, err := encryptGitHubToken("gho_supersecret")
+	assert.Error(t, err)
+}
+
+func TestGitHubOAuthEncryptionKey_RejectsWrongLength(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_ENCRYPTION_KEY", "too-short")
+	_, err := gitHubOAuthEncryptionKey()
+	assert.Error(t, err)
+}
+
+func TestOAuthState_RoundTripsBoundUserID(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_STATE_SECRET", "state-secret")
+
+	state, err := buildOAuthState("user-42")
+	assert.NoError(t, err)
+
+	userID, ok := oauthStateUserID(state)
+	assert.True(t, ok)
+	assert.Equal(t, "user-42", userID)
+}
+
+func TestOAuthState_RejectsTamperedUserID(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_STATE_SECRET", "state-secret")
+
+	state, err := buildOAuthState("user-42")
+	assert.NoError(t, err)
+
+	// Swap the state's signed user_id segment for a different user without
+	// recomputing the HMAC, simulating an attacker who completed their own
+	// OAuth round trip trying to bind the resulting token to someone else's
+	// account by tampering with the state.
+	parts := strings.SplitN(state, ".", 3)
+	assert.Len(t, parts, 3)
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("victim")) + "." + parts[2]
+
+	_, ok := oauthStateUserID(forged)
+	assert.False(t, ok)
+}
+
+func TestOAuthState_RejectsMalformedState(t *testing.T) {
+	_, ok := oauthStateUserID("not-a-valid-state")
+	assert.False(t, ok)
+}
+
+func TestOAuthState_RejectsStateSignedWithADifferentSecret(t *testing.T) {
+	t.Setenv("GITHUB_OAUTH_STATE_SECRET", "state-secret")
+	state, err := buildOAuthState("user-42")
+	assert.NoError(t, err)
+
+	t.Setenv("GITHUB_OAUTH_STATE_SECRET", "a-different-secret")
+	_, ok := oauthStateUserID(state)
+	assert.False(t, ok)
+}
+
+func TestJobGitHubTokenKey(t *testing.T) {
+	assert.Equal(t, "job:abc-123:github_token", jobGitHubTokenKey("abc-123"))
+}
+
+func TestRedactJobOptions_StripsGitHubToken(t *testing.T) {
+	job := &AnalysisJob{Options: map[string]string{"github_token": "gho_secret", "enable_lfs": "true"}}
+	redactJobOptions(job)
+	_, present := job.Options["github_token"]
+	assert.False(t, present)
+	assert.Equal(t, "true", job.Options["enable_lfs"])
+}
+
+func TestRedactJobOptions_NilOptionsIsNoop(t *testing.T) {
+	job := &AnalysisJob{}
+	redactJobOptions(job)
+	assert.Nil(t, job.Options)
+}
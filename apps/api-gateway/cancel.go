@@ -0,0 +1,374 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ensureCancelSchema adds the column recording who/what requested a job's
+// cancellation, in case an older schema migration hasn't created it yet.
+func ensureCancelSchema() error {
+	_, err := db.Exec(`
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(255);
+	`)
+	return err
+}
+
+// jobCancelKeyTTL bounds how long a job's cancel flag lives in Redis,
+// matching the window a worker could plausibly still be processing it in.
+const jobCancelKeyTTL = time.Hour
+
+// jobControlChannel is the Redis pub/sub channel workers subscribe to for
+// out-of-band control messages; cancellation is currently the only action
+// published on it.
+const jobControlChannel = "job_control"
+
+// defaultCancelGraceSeconds bounds how long cancelJob waits for a PROCESSING
+// job's worker to confirm it stopped before the gateway force-fails it.
+const defaultCancelGraceSeconds = 30
+
+// jobCancelKey is the Redis key an analyzer worker polls between analysis
+// phases to notice it should bail out cleanly.
+func jobCancelKey(jobID string) string {
+	return fmt.Sprintf("job:%s:cancel", jobID)
+}
+
+// jobCancelAckKey is the Redis key a worker sets via POST
+// /api/v1/jobs/:id/cancel/ack once it has actually stopped processing jobID,
+// letting the grace-period timer distinguish "cancel requested" from
+// "cancel confirmed".
+func jobCancelAckKey(jobID string) string {
+	return fmt.Sprintf("job:%s:cancel:ack", jobID)
+}
+
+// jobControlMessage is published to jobControlChannel so subscribed workers
+// learn about a cancellation without needing to poll jobCancelKey.
+type jobControlMessage struct {
+	JobID  string `json:"job_id"`
+	Action string `json:"action"`
+}
+
+// cancelGraceSeconds reads CANCEL_GRACE_SECONDS, falling back to
+// defaultCancelGraceSeconds when unset or invalid.
+func cancelGraceSeconds() int {
+	raw := getEnv("CANCEL_GRACE_SECONDS", "")
+	if raw == "" {
+		return defaultCancelGraceSeconds
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return defaultCancelGraceSeconds
+	}
+	return n
+}
+
+// cancelJob handles both DELETE /api/v1/jobs/:id and POST
+// /api/v1/jobs/:id/cancel, recording the cancellation as user-initiated.
+// See cancelJobWithReason for the shared cancellation path; autoCancelJob
+// uses the same path with a "system:superseded" reason when a newer push
+// makes an in-flight job stale.
+func cancelJob(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	updatedAt, currentStatus, err := cancelJobWithReason(jobID, "user:api")
+	if err != nil {
+		if err == errJobNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		if err == errJobNotCancellable {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":          "Job cannot be cancelled from its current status",
+				"current_status": currentStatus,
+			})
+			return
+		}
+		log.Printf("Failed to cancel job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel job"})
+		return
+	}
+
+	log.Printf("🛑 Cancelled job %s (was %s)", jobID, currentStatus)
+
+	c.JSON(http.StatusOK, JobUpdateResponse{
+		JobID:     jobID,
+		Status:    "CANCELLED",
+		Message:   "Job cancelled",
+		UpdatedAt: updatedAt,
+	})
+}
+
+// errJobNotFound and errJobNotCancellable let cancelJobWithReason report the
+// two "can't proceed" outcomes its callers (the HTTP handler and the
+// auto-cancel path) need to distinguish, without either depending on gin.
+var errJobNotFound = fmt.Errorf("job not found")
+var errJobNotCancellable = fmt.Errorf("job cannot be cancelled from its current status")
+
+// cancelJobWithReason marks jobID CANCELLED, recording cancelledBy
+// (e.g. "user:api" or "system:superseded") in analysis_jobs.cancellation_reason.
+// A QUEUED job is removed from analysis_queue before any worker can claim
+// it and marked CANCELLED immediately. A PROCESSING job is marked CANCELLED
+// right away too (so the coordinator and backlog see it as free), but since
+// a worker may already be mid-analysis, it also publishes to job_control and
+// sets the poll-based cancel flag, then starts a grace-period timer: if the
+// worker never acknowledges via /cancel/ack within CANCEL_GRACE_SECONDS, the
+// job is force-failed so it never looks stuck.
+func cancelJobWithReason(jobID, cancelledBy string) (updatedAt time.Time, currentStatus string, err error) {
+	currentStatus, err = cancelJobCurrentStatus(jobID)
+	if err == sql.ErrNoRows {
+		return time.Time{}, "", errJobNotFound
+	} else if err != nil {
+		return time.Time{}, "", fmt.Errorf("failed to retrieve job: %w", err)
+	}
+
+	if !validateStatusTransition(currentStatus, "CANCELLED", TransitionReasonAPI) {
+		return time.Time{}, currentStatus, errJobNotCancellable
+	}
+
+	if currentStatus == "QUEUED" {
+		if removed, err := removeQueuedJob(jobID); err != nil {
+			log.Printf("⚠️  Failed to remove job %s from analysis_queue: %v", jobID, err)
+		} else if !removed {
+			log.Printf("⚠️  Job %s was QUEUED but not found in analysis_queue", jobID)
+		}
+	}
+
+	if currentStatus == "PROCESSING" {
+		if err := redisClient.Set(ctx, jobCancelKey(jobID), "1", jobCancelKeyTTL).Err(); err != nil {
+			log.Printf("⚠️  Failed to set cancel flag for job %s: %v", jobID, err)
+		}
+		if err := publishJobCancel(jobID); err != nil {
+			log.Printf("⚠️  Failed to publish cancel control message for job %s: %v", jobID, err)
+		}
+		go watchCancelGrace(jobID, cancelGraceSeconds())
+	}
+
+	if _, err := db.Exec("UPDATE analysis_jobs SET cancellation_reason = $1 WHERE job_id = $2", cancelledBy, jobID); err != nil {
+		log.Printf("⚠️  Failed to record cancellation reason for job %s: %v", jobID, err)
+	}
+
+	cancelledStatus := "CANCELLED"
+	updatedAt, err = updateJobInDB(jobID, JobUpdateRequest{Status: &cancelledStatus})
+	if err != nil {
+		return time.Time{}, currentStatus, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	jobCoordinator.PromoteIfPending(jobID)
+	onWebhookJobComplete(jobID)
+	go finalizeJobLogs(jobID)
+
+	repoID, _ := resolveRepoUUID(jobID)
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:   "cancelled",
+		JobID:  jobID,
+		RepoID: repoID,
+		Status: "cancelled",
+		LogURL: jobLogTailURL(jobID),
+	})
+
+	go dispatchJobHooks(jobID, currentStatus, cancelledStatus, gin.H{
+		"job_id":      jobID,
+		"from_status": currentStatus,
+		"to_status":   cancelledStatus,
+		"cancelled_by": cancelledBy,
+		"timestamp":   time.Now().UTC(),
+	})
+
+	return updatedAt, currentStatus, nil
+}
+
+func cancelJobCurrentStatus(jobID string) (string, error) {
+	var currentStatus string
+	err := db.QueryRow("SELECT status FROM analysis_jobs WHERE job_id = $1", jobID).Scan(&currentStatus)
+	return currentStatus, err
+}
+
+// ackJobCancel handles POST /api/v1/jobs/:id/cancel/ack: a worker calls this
+// once it has actually torn down its in-flight analysis for jobID, so
+// watchCancelGrace knows not to force-fail it.
+func ackJobCancel(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	if err := redisClient.Set(ctx, jobCancelAckKey(jobID), "1", jobCancelKeyTTL).Err(); err != nil {
+		log.Printf("⚠️  Failed to set cancel ack for job %s: %v", jobID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record cancel acknowledgement"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// publishJobCancel notifies workers subscribed to job_control that jobID
+// should stop, for workers that prefer pub/sub over polling jobCancelKey.
+func publishJobCancel(jobID string) error {
+	payload, err := json.Marshal(jobControlMessage{JobID: jobID, Action: "cancel"})
+	if err != nil {
+		return err
+	}
+	return redisClient.Publish(ctx, jobControlChannel, payload).Err()
+}
+
+// removeQueuedJob atomically removes jobID's payload from analysis_queue so
+// a worker can never pop a cancelled QUEUED job, reporting whether an entry
+// was actually found and removed.
+func removeQueuedJob(jobID string) (bool, error) {
+	raw, err := redisClient.LRange(ctx, "analysis_queue", 0, -1).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to read analysis_queue: %w", err)
+	}
+
+	for _, entry := range raw {
+		var job AnalysisJob
+		if err := json.Unmarshal([]byte(entry), &job); err != nil {
+			continue
+		}
+		if job.JobID != jobID {
+			continue
+		}
+		removedCount, err := redisClient.LRem(ctx, "analysis_queue", 1, entry).Result()
+		if err != nil {
+			return false, fmt.Errorf("failed to remove job from analysis_queue: %w", err)
+		}
+		return removedCount > 0, nil
+	}
+	return false, nil
+}
+
+// watchCancelGrace waits graceSeconds for a worker to call /cancel/ack for
+// jobID. If no acknowledgement arrives in time, the job is force-failed so
+// it never looks stuck in CANCELLED/PROCESSING limbo from a worker that
+// ignored (or never saw) the cancellation signal.
+func watchCancelGrace(jobID string, graceSeconds int) {
+	time.Sleep(time.Duration(graceSeconds) * time.Second)
+
+	acked, err := redisClient.Exists(ctx, jobCancelAckKey(jobID)).Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to check cancel ack for job %s: %v", jobID, err)
+		return
+	}
+	if acked > 0 {
+		return
+	}
+
+	failedStatus := "FAILED"
+	timeoutErr := "cancellation timeout"
+	if _, err := db.Exec(`
+		UPDATE analysis_jobs
+		SET status = $1, error_message = $2, completed_at = now()
+		WHERE job_id = $3 AND status != 'FAILED' AND status != 'COMPLETED'
+	`, failedStatus, timeoutErr, jobID); err != nil {
+		log.Printf("⚠️  Failed to force-fail job %s after cancel grace period: %v", jobID, err)
+		return
+	}
+
+	log.Printf("⏱️  Job %s did not confirm cancellation within %ds; marked FAILED", jobID, graceSeconds)
+	finalizeJobLogs(jobID)
+
+	repoID, _ := resolveRepoUUID(jobID)
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:   "status",
+		JobID:  jobID,
+		RepoID: repoID,
+		Status: "failed",
+		Error:  timeoutErr,
+		LogURL: jobLogTailURL(jobID),
+	})
+}
+
+// isJobCancelled reports whether a worker should bail out of job jobID;
+// analyzer workers are expected to poll this between analysis phases.
+func isJobCancelled(jobID string) bool {
+	exists, err := redisClient.Exists(ctx, jobCancelKey(jobID)).Result()
+	return err == nil && exists > 0
+}
+
+// BacklogEntry reports one repo's queue depth and pending job ordering for
+// GET /api/v1/jobs/backlog.
+type BacklogEntry struct {
+	RepoID       string   `json:"repo_id"`
+	QueuedCount  int      `json:"queued_count"`
+	RunningJobID string   `json:"running_job_id,omitempty"`
+	QueuedJobIDs []string `json:"queued_job_ids"`
+}
+
+// listJobBacklog handles GET /api/v1/jobs/backlog: per-repo queue depth
+// (from the Redis analysis_queue list) plus each repo's in-flight job.
+func listJobBacklog(c *gin.Context) {
+	entries, err := buildJobBacklog()
+	if err != nil {
+		log.Printf("Failed to build job backlog: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve backlog"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"backlog": entries})
+}
+
+func buildJobBacklog() ([]BacklogEntry, error) {
+	// analysis_queue is pushed via LPush and drained via RPop/BRPop, so the
+	// tail of the list is the next job a worker will pick up; LRange
+	// returns head-to-tail, so we walk it in reverse to report queue
+	// position soonest-first.
+	raw, err := redisClient.LRange(ctx, "analysis_queue", 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analysis_queue: %w", err)
+	}
+
+	byRepo := make(map[string]*BacklogEntry)
+	order := []string{}
+	for i := len(raw) - 1; i >= 0; i-- {
+		var job AnalysisJob
+		if err := json.Unmarshal([]byte(raw[i]), &job); err != nil {
+			continue
+		}
+		entry, ok := byRepo[job.RepoID]
+		if !ok {
+			entry = &BacklogEntry{RepoID: job.RepoID}
+			byRepo[job.RepoID] = entry
+			order = append(order, job.RepoID)
+		}
+		entry.QueuedJobIDs = append(entry.QueuedJobIDs, job.JobID)
+		entry.QueuedCount++
+	}
+
+	rows, err := db.Query(`SELECT job_id, repo_url FROM analysis_jobs WHERE status = 'PROCESSING'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read running jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobID, repoURL string
+		if err := rows.Scan(&jobID, &repoURL); err != nil {
+			continue
+		}
+		repoID := generateRepoID(repoURL)
+		entry, ok := byRepo[repoID]
+		if !ok {
+			entry = &BacklogEntry{RepoID: repoID}
+			byRepo[repoID] = entry
+			order = append(order, repoID)
+		}
+		entry.RunningJobID = jobID
+	}
+
+	entries := make([]BacklogEntry, 0, len(order))
+	for _, repoID := range order {
+		entries = append(entries, *byRepo[repoID])
+	}
+	return entries, nil
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// jobCoordinator coalesces direct POST /api/v1/analyze requests for the same
+// repo+branch: while a job is already active, further requests merge into a
+// single pending slot instead of each queuing their own job. Initialized in
+// main().
+var jobCoordinator *JobCoordinator
+
+// coalesceKey identifies one repo+branch's in-flight job coordination state.
+type coalesceKey struct {
+	repoID string
+	branch string
+}
+
+// pendingAnalysis is the single backlogged request waiting for the active
+// job to finish, holding the newest ref/options and every request ID it
+// absorbed along the way.
+type pendingAnalysis struct {
+	repoURL       string
+	branch        string
+	options       map[string]string
+	coalescedFrom []string
+}
+
+// merge folds another request into the pending slot: last-write-wins for
+// repoURL/branch/options, with virtualID appended to the merge history.
+func (p *pendingAnalysis) merge(repoURL, branch string, options map[string]string, virtualID string) {
+	p.repoURL = repoURL
+	p.branch = branch
+	p.options = options
+	p.coalescedFrom = append(p.coalescedFrom, virtualID)
+}
+
+// coalesceState tracks one key's active job plus, if a request arrived while
+// it was running, the single merged pending slot behind it.
+type coalesceState struct {
+	activeJobID string
+	pending     *pendingAnalysis
+}
+
+// JobCoordinator coalesces duplicate analysis requests for the same
+// repoID#branch into a single queued job, following the three-state
+// (active/pending/empty) pattern: an empty key queues immediately, an active
+// key merges into its pending slot, and a key with a pending slot just
+// updates it in place.
+type JobCoordinator struct {
+	mu sync.Mutex
+	// keys is indexed by repoID#branch.
+	keys map[coalesceKey]*coalesceState
+	// byJobID maps an active job's ID back to its key, so updateJob can find
+	// which key to promote when that job reaches a terminal status.
+	byJobID map[string]coalesceKey
+}
+
+// NewJobCoordinator builds an empty coordinator.
+func NewJobCoordinator() *JobCoordinator {
+	return &JobCoordinator{
+		keys:    make(map[coalesceKey]*coalesceState),
+		byJobID: make(map[string]coalesceKey),
+	}
+}
+
+// ensureJobBacklogSchema creates the job_backlog table used to persist
+// coordinator state so a gateway restart doesn't lose a merged pending
+// request.
+func ensureJobBacklogSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS job_backlog (
+			repo_id VARCHAR(255) NOT NULL,
+			branch VARCHAR(255) NOT NULL,
+			active_job_id VARCHAR(255) NOT NULL,
+			pending_repo_url TEXT,
+			pending_branch VARCHAR(255),
+			pending_options JSONB,
+			coalesced_from JSONB,
+			updated_at TIMESTAMP NOT NULL DEFAULT now(),
+			PRIMARY KEY (repo_id, branch)
+		);
+	`)
+	return err
+}
+
+// Submit registers an analysis request for repoURL/branch. If the key is
+// empty, it queues a new job immediately (jobID, coalesced=false). If a job
+// is already active for this key, the request merges into the pending slot
+// (last-write-wins for branch/options) and the caller gets back the
+// already-running job's ID with coalesced=true instead of a new job.
+func (jc *JobCoordinator) Submit(repoURL, branch string, options map[string]string) (jobID string, coalesced bool, coalescedFrom []string, err error) {
+	key := coalesceKey{repoID: generateRepoID(repoURL), branch: branch}
+
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	state, ok := jc.keys[key]
+	if !ok {
+		newJobID, createErr := createQueuedAnalysisJob(repoURL, branch, options)
+		if createErr != nil {
+			return "", false, nil, createErr
+		}
+		jc.keys[key] = &coalesceState{activeJobID: newJobID}
+		jc.byJobID[newJobID] = key
+		if persistErr := persistActiveBacklog(key, newJobID); persistErr != nil {
+			log.Printf("⚠️  Failed to persist job_backlog for %s#%s: %v", key.repoID, key.branch, persistErr)
+		}
+		return newJobID, false, nil, nil
+	}
+
+	virtualID := uuid.New().String()
+	if state.pending == nil {
+		state.pending = &pendingAnalysis{}
+	}
+	state.pending.merge(repoURL, branch, options, virtualID)
+
+	if persistErr := persistPendingBacklog(key, state); persistErr != nil {
+		log.Printf("⚠️  Failed to persist pending job_backlog for %s#%s: %v", key.repoID, key.branch, persistErr)
+	}
+
+	return state.activeJobID, true, append([]string{}, state.pending.coalescedFrom...), nil
+}
+
+// PromoteIfPending is called once a job reaches a terminal status. If that
+// job was the active job for a coordinated key, it frees the key; if a
+// pending slot was waiting behind it, that slot is promoted to a freshly
+// queued job.
+func (jc *JobCoordinator) PromoteIfPending(jobID string) {
+	jc.mu.Lock()
+	key, ok := jc.byJobID[jobID]
+	if !ok {
+		jc.mu.Unlock()
+		return
+	}
+	delete(jc.byJobID, jobID)
+
+	state := jc.keys[key]
+	if state == nil || state.pending == nil {
+		delete(jc.keys, key)
+		jc.mu.Unlock()
+		if err := deleteJobBacklog(key); err != nil {
+			log.Printf("⚠️  Failed to clear job_backlog for %s#%s: %v", key.repoID, key.branch, err)
+		}
+		return
+	}
+
+	pending := state.pending
+	jc.mu.Unlock()
+
+	newJobID, err := createQueuedAnalysisJob(pending.repoURL, pending.branch, pending.options)
+	if err != nil {
+		log.Printf("❌ Coordinator: failed to promote pending analysis for %s#%s: %v", key.repoID, key.branch, err)
+		return
+	}
+
+	jc.mu.Lock()
+	jc.keys[key] = &coalesceState{activeJobID: newJobID}
+	jc.byJobID[newJobID] = key
+	jc.mu.Unlock()
+
+	if err := persistActiveBacklog(key, newJobID); err != nil {
+		log.Printf("⚠️  Failed to persist promoted job_backlog for %s#%s: %v", key.repoID, key.branch, err)
+	}
+
+	log.Printf("⬆️  Coordinator: promoted pending analysis to job %s for %s#%s (%d requests merged)", newJobID, key.repoID, key.branch, len(pending.coalescedFrom))
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:      "coalesced",
+		JobID:     newJobID,
+		RepoID:    key.repoID,
+		Message:   "Pending analysis promoted after prior job completed",
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// createQueuedAnalysisJob stores and queues a brand new analysis job,
+// exactly like analyzeRepository's pre-coordinator behavior.
+func createQueuedAnalysisJob(repoURL, branch string, options map[string]string) (string, error) {
+	jobID := uuid.New().String()
+	job := AnalysisJob{
+		JobID:     jobID,
+		RepoID:    generateRepoID(repoURL),
+		RepoURL:   repoURL,
+		Branch:    branch,
+		Status:    "QUEUED",
+		Options:   options,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := storeJob(job); err != nil {
+		return "", err
+	}
+
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+
+	if err := redisClient.LPush(ctx, "analysis_queue", jobJSON).Err(); err != nil {
+		return "", err
+	}
+
+	return jobID, nil
+}
+
+func persistActiveBacklog(key coalesceKey, activeJobID string) error {
+	_, err := db.Exec(`
+		INSERT INTO job_backlog (repo_id, branch, active_job_id, pending_repo_url, pending_branch, pending_options, coalesced_from, updated_at)
+		VALUES ($1, $2, $3, NULL, NULL, NULL, NULL, now())
+		ON CONFLICT (repo_id, branch) DO UPDATE SET
+			active_job_id = EXCLUDED.active_job_id,
+			pending_repo_url = NULL,
+			pending_branch = NULL,
+			pending_options = NULL,
+			coalesced_from = NULL,
+			updated_at = now()
+	`, key.repoID, key.branch, activeJobID)
+	return err
+}
+
+func persistPendingBacklog(key coalesceKey, state *coalesceState) error {
+	optionsJSON, err := json.Marshal(state.pending.options)
+	if err != nil {
+		return err
+	}
+	coalescedJSON, err := json.Marshal(state.pending.coalescedFrom)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		UPDATE job_backlog
+		SET pending_repo_url = $3, pending_branch = $4, pending_options = $5, coalesced_from = $6, updated_at = now()
+		WHERE repo_id = $1 AND branch = $2
+	`, key.repoID, key.branch, state.pending.repoURL, state.pending.branch, optionsJSON, coalescedJSON)
+	return err
+}
+
+func deleteJobBacklog(key coalesceKey) error {
+	_, err := db.Exec("DELETE FROM job_backlog WHERE repo_id = $1 AND branch = $2", key.repoID, key.branch)
+	return err
+}
+
+// loadJobBacklog repopulates the in-memory coordinator from job_backlog on
+// startup, so a gateway restart doesn't forget a merged pending request.
+func loadJobBacklog() error {
+	rows, err := db.Query(`
+		SELECT repo_id, branch, active_job_id, pending_repo_url, pending_branch, pending_options, coalesced_from
+		FROM job_backlog
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	jobCoordinator.mu.Lock()
+	defer jobCoordinator.mu.Unlock()
+
+	for rows.Next() {
+		var repoID, branch, activeJobID string
+		var pendingRepoURL, pendingBranch sql.NullString
+		var pendingOptionsJSON, coalescedFromJSON []byte
+		if err := rows.Scan(&repoID, &branch, &activeJobID, &pendingRepoURL, &pendingBranch, &pendingOptionsJSON, &coalescedFromJSON); err != nil {
+			log.Printf("⚠️  Failed to scan job_backlog row: %v", err)
+			continue
+		}
+
+		key := coalesceKey{repoID: repoID, branch: branch}
+		state := &coalesceState{activeJobID: activeJobID}
+
+		if pendingRepoURL.Valid {
+			var options map[string]string
+			_ = json.Unmarshal(pendingOptionsJSON, &options)
+			var coalescedFrom []string
+			_ = json.Unmarshal(coalescedFromJSON, &coalescedFrom)
+			state.pending = &pendingAnalysis{
+				repoURL:       pendingRepoURL.String,
+				branch:        pendingBranch.String,
+				options:       options,
+				coalescedFrom: coalescedFrom,
+			}
+		}
+
+		jobCoordinator.keys[key] = state
+		jobCoordinator.byJobID[activeJobID] = key
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingAnalysis_MergeIsLastWriteWinsAndAccumulatesHistory(t *testing.T) {
+	p := &pendingAnalysis{}
+
+	p.merge("https://github.com/foo/bar", "main", map[string]string{"mode": "full"}, "req-1")
+	p.merge("https://github.com/foo/bar", "develop", map[string]string{"mode": "incremental"}, "req-2")
+
+	assert.Equal(t, "develop", p.branch)
+	assert.Equal(t, "incremental", p.options["mode"])
+	assert.Equal(t, []string{"req-1", "req-2"}, p.coalescedFrom)
+}
+
+func TestCoalesceKey_IsComparableForMapUse(t *testing.T) {
+	keys := map[coalesceKey]bool{}
+	keys[coalesceKey{repoID: "r1", branch: "main"}] = true
+
+	assert.True(t, keys[coalesceKey{repoID: "r1", branch: "main"}])
+	assert.False(t, keys[coalesceKey{repoID: "r1", branch: "develop"}])
+}
+
+func TestNewJobCoordinator_StartsEmpty(t *testing.T) {
+	jc := NewJobCoordinator()
+	assert.Empty(t, jc.keys)
+	assert.Empty(t, jc.byJobID)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// debouncer coalesces rapid webhook pushes into a single AnalysisJob per
+// repo+branch quiet period. Initialized in main().
+var debouncer *AnalysisDebouncer
+
+// pushEvent is one webhook push fed into a debounce window.
+type pushEvent struct {
+	before       string
+	after        string
+	changedFiles []string
+	removedFiles []string
+}
+
+// debounceKey identifies one open debounce window.
+type debounceKey struct {
+	repoID string
+	branch string
+}
+
+// debounceWindow is the channel pair backing a single in-flight window, per
+// the "push channel resets the timer, fire channel creates the job"
+// pattern.
+type debounceWindow struct {
+	pushCh  chan pushEvent
+	flushCh chan struct{}
+}
+
+// AnalysisDebouncer holds one goroutine-backed debounceWindow per
+// (repo_id, branch) key, coalescing pushes received within the quiet
+// period into a single AnalysisJob covering their combined range.
+type AnalysisDebouncer struct {
+	mu      sync.Mutex
+	windows map[debounceKey]*debounceWindow
+	quiet   time.Duration
+}
+
+// NewAnalysisDebouncer creates a debouncer with the given quiet period.
+func NewAnalysisDebouncer(quiet time.Duration) *AnalysisDebouncer {
+	return &AnalysisDebouncer{
+		windows: make(map[debounceKey]*debounceWindow),
+		quiet:   quiet,
+	}
+}
+
+// analysisDebounceWindow reads ANALYSIS_DEBOUNCE_MS, defaulting to 20s.
+func analysisDebounceWindow() time.Duration {
+	ms := 20000
+	if v := getEnv("ANALYSIS_DEBOUNCE_MS", ""); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			ms = parsed
+		}
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Push feeds a webhook push into the debounce window for repoURL/branch,
+// starting the window's goroutine on first use. It returns immediately; the
+// coalesced AnalysisJob is created asynchronously once the quiet period
+// elapses with no further pushes.
+func (d *AnalysisDebouncer) Push(repoURL, branch string, evt pushEvent) {
+	key := debounceKey{repoID: generateRepoID(repoURL), branch: branch}
+
+	d.mu.Lock()
+	w, ok := d.windows[key]
+	if !ok {
+		w = &debounceWindow{
+			pushCh:  make(chan pushEvent, 16),
+			flushCh: make(chan struct{}, 1),
+		}
+		d.windows[key] = w
+		go d.run(key, repoURL, w)
+	}
+	d.mu.Unlock()
+
+	w.pushCh <- evt
+}
+
+// Flush force-fires every open window for repoID across all branches,
+// returning how many windows it flushed.
+func (d *AnalysisDebouncer) Flush(repoID string) int {
+	d.mu.Lock()
+	var windows []*debounceWindow
+	for key, w := range d.windows {
+		if key.repoID == repoID {
+			windows = append(windows, w)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, w := range windows {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return len(windows)
+}
+
+// run accumulates pushes for key until the quiet period elapses or a flush
+// is requested, then submits the coalesced push once through
+// submitWebhookAnalysisJob and exits; that creates one AnalysisJob, unless
+// jobTracker merges the push into a running job's Pending slot or drops it
+// as a near-duplicate. The next push for this key starts a fresh window via
+// Push.
+func (d *AnalysisDebouncer) run(key debounceKey, repoURL string, w *debounceWindow) {
+	var acc pushEvent
+	pushes := 0
+
+	timer := time.NewTimer(d.quiet)
+	defer timer.Stop()
+
+	resetTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(d.quiet)
+	}
+
+	fire := func() {
+		if pushes == 0 {
+			return
+		}
+
+		jobID, merged, dropped, err := submitWebhookAnalysisJob(repoURL, key.branch, "push", acc.before, acc.after, acc.changedFiles, acc.removedFiles)
+		if err != nil {
+			log.Printf("❌ Debouncer: failed to create coalesced analysis job for %s@%s: %v", repoURL, key.branch, err)
+			return
+		}
+		if dropped {
+			log.Printf("ℹ️ Debouncer: dropped near-duplicate push for %s@%s", repoURL, key.branch)
+			return
+		}
+		if merged {
+			log.Printf("⏳ Debouncer: %s@%s's job is still running, merged into its pending slot (%d pushes)", repoURL, key.branch, pushes)
+			return
+		}
+
+		log.Printf("✅ Debouncer: created coalesced analysis job %s for %s@%s (%d pushes merged)", jobID, repoURL, key.branch, pushes)
+		wsHub.BroadcastJobUpdate(JobUpdate{
+			Type:      "coalesced",
+			JobID:     jobID,
+			RepoID:    key.repoID,
+			Message:   fmt.Sprintf("%d pushes merged", pushes),
+			Timestamp: time.Now().UTC(),
+		})
+	}
+
+	for {
+		select {
+		case evt := <-w.pushCh:
+			if pushes == 0 {
+				acc.before = evt.before
+			}
+			acc.after = evt.after
+			acc.changedFiles = unionFiles(acc.changedFiles, evt.changedFiles)
+			acc.removedFiles = unionFiles(acc.removedFiles, evt.removedFiles)
+			pushes++
+			resetTimer()
+
+		case <-timer.C:
+			fire()
+			d.close(key)
+			return
+
+		case <-w.flushCh:
+			fire()
+			d.close(key)
+			return
+		}
+	}
+}
+
+func (d *AnalysisDebouncer) close(key debounceKey) {
+	d.mu.Lock()
+	delete(d.windows, key)
+	d.mu.Unlock()
+}
+
+// unionFiles merges incoming into existing, de-duplicating and preserving
+// first-seen order.
+func unionFiles(existing, incoming []string) []string {
+	if len(incoming) == 0 {
+		return existing
+	}
+	seen := make(map[string]bool, len(existing)+len(incoming))
+	out := make([]string, 0, len(existing)+len(incoming))
+	for _, f := range existing {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	for _, f := range incoming {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// flushAnalysisDebounce is the admin endpoint POST /api/v1/jobs/flush/:repo_id
+// that force-fires any open debounce window for repoID instead of waiting
+// out the quiet period.
+func flushAnalysisDebounce(c *gin.Context) {
+	repoID := c.Param("repo_id")
+	flushed := debouncer.Flush(repoID)
+	c.JSON(http.StatusOK, gin.H{
+		"repo_id": repoID,
+		"flushed": flushed,
+	})
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnionFiles_DeduplicatesPreservingOrder(t *testing.T) {
+	existing := []string{"a.go", "b.go"}
+	incoming := []string{"b.go", "c.go"}
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, unionFiles(existing, incoming))
+}
+
+func TestUnionFiles_NoIncomingReturnsExisting(t *testing.T) {
+	existing := []string{"a.go"}
+	assert.Equal(t, existing, unionFiles(existing, nil))
+}
+
+func TestAnalysisDebounceWindow_DefaultsTo20Seconds(t *testing.T) {
+	assert.Equal(t, 20*time.Second, analysisDebounceWindow())
+}
+
+func TestAnalysisDebouncer_FlushOnUnknownRepoIsANoop(t *testing.T) {
+	d := NewAnalysisDebouncer(time.Minute)
+	assert.Equal(t, 0, d.Flush("does-not-exist"))
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// webhookRetryQueueKey is a Redis sorted set of inbound webhook deliveries
+// waiting out their retry backoff, scored by the unix millisecond timestamp
+// they become due. Mirrors hookEventsScheduledKey's shape for outbound hooks.
+const webhookRetryQueueKey = "webhook_retry_queue"
+
+// webhookRetryDeadKey holds inbound deliveries that exhausted
+// webhookRetryBackoff.
+const webhookRetryDeadKey = "webhook_retry_queue:dead"
+
+// webhookRetryBackoff is the delay before each retry of a failed inbound
+// delivery: 1m, 5m, 15m, 1h, 6h. Once exhausted the delivery is dead-lettered.
+var webhookRetryBackoff = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	1 * time.Hour,
+	6 * time.Hour,
+}
+
+// webhookDeliveryTimestampWindow bounds how stale a provider-supplied
+// delivery timestamp may be before it's rejected as a possible replay.
+const webhookDeliveryTimestampWindow = 5 * time.Minute
+
+// WebhookDelivery is one row of the webhook_deliveries ledger: one per
+// distinct provider delivery ID, used to reject duplicates from a
+// provider's at-least-once retry semantics.
+type WebhookDelivery struct {
+	DeliveryID  string     `json:"delivery_id"`
+	Provider    string     `json:"provider"`
+	RepoID      string     `json:"repo_id,omitempty"`
+	ReceivedAt  time.Time  `json:"received_at"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Result      string     `json:"result,omitempty"`
+	JobID       string     `json:"job_id,omitempty"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// WebhookRetryItem is the envelope persisted to webhookRetryQueueKey so a
+// failed delivery can be reprocessed without the original HTTP request.
+type WebhookRetryItem struct {
+	DeliveryID string      `json:"delivery_id"`
+	Provider   string      `json:"provider"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"`
+	Attempt    int         `json:"attempt"`
+}
+
+// ensureWebhookDeliveriesSchema creates the webhook_deliveries ledger if
+// migrations were not applied. headers/payload retain the full raw delivery
+// (persisted before any parsing happens) so a failed or dropped delivery can
+// be redelivered from its original bytes; processed_at/result/job_id record
+// the outcome once the handler (or a replay) finishes with it.
+func ensureWebhookDeliveriesSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			delivery_id VARCHAR(255) PRIMARY KEY,
+			provider VARCHAR(32) NOT NULL,
+			repo_id VARCHAR(255),
+			signature TEXT,
+			status VARCHAR(32) NOT NULL DEFAULT 'received',
+			attempts INTEGER NOT NULL DEFAULT 1,
+			received_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_repo_id ON webhook_deliveries(repo_id);
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS headers JSONB;
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS payload BYTEA;
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS processed_at TIMESTAMPTZ;
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS result VARCHAR(32);
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS job_id VARCHAR(64);
+	`)
+	return err
+}
+
+// deliveryIDForRequest returns the provider's per-delivery idempotency
+// header, or "" if this provider doesn't send one (GitLab's token-auth
+// webhooks carry no delivery ID, so duplicates can't be detected there).
+func deliveryIDForRequest(provider string, headers http.Header) string {
+	switch provider {
+	case "github":
+		return headers.Get("X-GitHub-Delivery")
+	case "gitea":
+		return headers.Get("X-Gitea-Delivery")
+	case "bitbucket":
+		return headers.Get("X-Request-UUID")
+	default:
+		return ""
+	}
+}
+
+// withinReplayWindow reports whether a provider-supplied delivery
+// timestamp header is within webhookDeliveryTimestampWindow of now. Most
+// forges don't send one; absence is treated as "nothing to check" rather
+// than a rejection, since this guard only adds value on top of signature
+// verification, never in place of it.
+func withinReplayWindow(headers http.Header) bool {
+	raw := headers.Get("X-ArchMind-Webhook-Timestamp")
+	if raw == "" {
+		return true
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false
+	}
+	sentAt := time.Unix(seconds, 0)
+	return time.Since(sentAt).Abs() <= webhookDeliveryTimestampWindow
+}
+
+// recordDelivery inserts a new webhook_deliveries row — persisting the raw
+// headers and body before any parsing happens, so a delivery that fails
+// parsing, signature verification, or job creation can still be redelivered
+// from its original bytes — and reports whether deliveryID had already been
+// seen (in which case the insert is a no-op and the caller should treat the
+// request as a duplicate to ignore).
+func recordDelivery(deliveryID, provider, repoID, signature string, headers http.Header, payload []byte) (duplicate bool, err error) {
+	if deliveryID == "" {
+		return false, nil
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal delivery headers: %w", err)
+	}
+	result, err := db.Exec(`
+		INSERT INTO webhook_deliveries (delivery_id, provider, repo_id, signature, headers, payload, status, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, 'received', 1)
+		ON CONFLICT (delivery_id) DO NOTHING
+	`, deliveryID, provider, repoID, signature, headersJSON, payload)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 0, nil
+}
+
+// recordDeliveryResult records the outcome of processing deliveryID once the
+// handler (or a manual replay) finishes with it, so GET
+// /api/v1/webhooks/deliveries can show what a delivery actually did without
+// needing to cross-reference analysis_jobs.
+func recordDeliveryResult(deliveryID, jobID, result string) {
+	if deliveryID == "" {
+		return
+	}
+	if _, err := db.Exec(`
+		UPDATE webhook_deliveries SET result = $2, job_id = $3, processed_at = now() WHERE delivery_id = $1
+	`, deliveryID, result, jobID); err != nil {
+		log.Printf("⚠️  Failed to record result for delivery %s: %v", deliveryID, err)
+	}
+}
+
+func updateDeliveryStatus(deliveryID, status string) {
+	if deliveryID == "" {
+		return
+	}
+	if _, err := db.Exec("UPDATE webhook_deliveries SET status = $2 WHERE delivery_id = $1", deliveryID, status); err != nil {
+		log.Printf("⚠️  Failed to update delivery %s status to %s: %v", deliveryID, status, err)
+	}
+}
+
+func incrementDeliveryAttempts(deliveryID string) {
+	if deliveryID == "" {
+		return
+	}
+	if _, err := db.Exec("UPDATE webhook_deliveries SET attempts = attempts + 1 WHERE delivery_id = $1", deliveryID); err != nil {
+		log.Printf("⚠️  Failed to bump delivery %s attempts: %v", deliveryID, err)
+	}
+}
+
+// enqueueWebhookRetry schedules item for another attempt after the backoff
+// delay for its attempt number, or dead-letters it once webhookRetryBackoff
+// is exhausted.
+func enqueueWebhookRetry(item WebhookRetryItem) {
+	if item.Attempt > len(webhookRetryBackoff) {
+		deadLetterWebhookDelivery(item)
+		return
+	}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal webhook retry item %s: %v", item.DeliveryID, err)
+		return
+	}
+
+	delay := webhookRetryBackoff[item.Attempt-1]
+	dueAt := float64(time.Now().Add(delay).UnixMilli())
+	if err := redisClient.ZAdd(ctx, webhookRetryQueueKey, &redis.Z{Score: dueAt, Member: string(body)}).Err(); err != nil {
+		log.Printf("⚠️  Failed to schedule webhook retry %s: %v", item.DeliveryID, err)
+		return
+	}
+	updateDeliveryStatus(item.DeliveryID, "retry_scheduled")
+	log.Printf("🔁 Webhook delivery %s scheduled for retry %d/%d in %s", item.DeliveryID, item.Attempt, len(webhookRetryBackoff), delay)
+}
+
+func deadLetterWebhookDelivery(item WebhookRetryItem) {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+	if err := redisClient.LPush(ctx, webhookRetryDeadKey, body).Err(); err != nil {
+		log.Printf("⚠️  Failed to dead-letter webhook delivery %s: %v", item.DeliveryID, err)
+	}
+	updateDeliveryStatus(item.DeliveryID, "failed_permanently")
+	log.Printf("💀 Webhook delivery %s dead-lettered after %d attempts", item.DeliveryID, item.Attempt-1)
+}
+
+// reprocessGitHubDelivery replays a previously-failed GitHub delivery
+// outside of an HTTP request, mirroring handleGitHubWebhook's verify+route
+// steps without needing a gin.Context to write a response into.
+func reprocessGitHubDelivery(headers http.Header, body []byte) error {
+	eventType := headers.Get("X-GitHub-Event")
+	contentType := headers.Get("Content-Type")
+
+	payloadBody := body
+	if eventType == "push" || eventType == "pull_request" || eventType == "ping" {
+		var err error
+		payloadBody, err = extractGitHubJSONPayload(body, contentType)
+		if err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+	}
+
+	secretOverride := resolveWebhookSecret(eventType, payloadBody)
+	if !verifyGitHubSignature(body, headers.Get("X-Hub-Signature-256"), secretOverride) {
+		return fmt.Errorf("signature verification failed")
+	}
+
+	switch eventType {
+	case "push":
+		var payload GitHubPushPayload
+		if err := json.Unmarshal(payloadBody, &payload); err != nil {
+			return fmt.Errorf("invalid push payload: %w", err)
+		}
+		changedFiles := collectChangedFiles(payload.Commits)
+		removedFiles := collectRemovedFiles(payload.Commits)
+		if !hasAnalyzableFiles(append(append([]string{}, changedFiles...), removedFiles...)) {
+			return nil
+		}
+		debouncer.Push(payload.Repository.CloneURL, extractBranchName(payload.Ref), pushEvent{
+			before:       payload.Before,
+			after:        payload.After,
+			changedFiles: changedFiles,
+			removedFiles: removedFiles,
+		})
+		return nil
+	case "pull_request":
+		var payload GitHubPullRequestPayload
+		if err := json.Unmarshal(payloadBody, &payload); err != nil {
+			return fmt.Errorf("invalid pull_request payload: %w", err)
+		}
+		validActions := map[string]bool{"opened": true, "synchronize": true, "reopened": true}
+		if !validActions[payload.Action] {
+			return nil
+		}
+		// Route through submitWebhookAnalysisJob (not createWebhookAnalysisJob
+		// directly) so a retried or manually-replayed delivery gets the same
+		// jobTracker coalescing/auto-cancel-superseded treatment as the live
+		// path in handlePullRequestEvent, instead of being able to spawn a
+		// duplicate job racing one the tracker already considers Active for
+		// this ref.
+		jobID, merged, dropped, err := submitWebhookAnalysisJob(
+			payload.Repository.CloneURL,
+			payload.PullRequest.Head.Ref,
+			"pull_request",
+			"",
+			payload.PullRequest.Head.SHA,
+			nil,
+			nil,
+		)
+		if err != nil || merged || dropped {
+			return err
+		}
+		go publishPendingCommitStatus(payload.Repository.CloneURL, payload.PullRequest.Head.SHA, jobID)
+		return nil
+	default:
+		return nil
+	}
+}
+
+// WebhookRetryDispatcher drains webhookRetryQueueKey on an interval,
+// reprocessing deliveries whose backoff has elapsed.
+type WebhookRetryDispatcher struct {
+	pollInterval time.Duration
+}
+
+// NewWebhookRetryDispatcher builds a dispatcher that polls every
+// pollInterval.
+func NewWebhookRetryDispatcher(pollInterval time.Duration) *WebhookRetryDispatcher {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &WebhookRetryDispatcher{pollInterval: pollInterval}
+}
+
+// Run blocks, reprocessing due webhook deliveries until stop is closed.
+func (d *WebhookRetryDispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.processDue()
+		}
+	}
+}
+
+func (d *WebhookRetryDispatcher) processDue() {
+	nowMs := float64(time.Now().UnixMilli())
+	due, err := redisClient.ZRangeByScore(ctx, webhookRetryQueueKey, &redis.ZRangeBy{
+		Min: "0",
+		Max: fmt.Sprintf("%f", nowMs),
+	}).Result()
+	if err != nil || len(due) == 0 {
+		return
+	}
+
+	for _, raw := range due {
+		redisClient.ZRem(ctx, webhookRetryQueueKey, raw)
+
+		var item WebhookRetryItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			log.Printf("⚠️  Failed to decode webhook retry item: %v", err)
+			continue
+		}
+
+		if err := reprocessWebhookDelivery(item); err != nil {
+			log.Printf("⚠️  Webhook retry %s failed (attempt %d): %v", item.DeliveryID, item.Attempt, err)
+			incrementDeliveryAttempts(item.DeliveryID)
+			item.Attempt++
+			enqueueWebhookRetry(item)
+			continue
+		}
+
+		updateDeliveryStatus(item.DeliveryID, "delivered")
+		log.Printf("✅ Webhook retry %s succeeded (attempt %d)", item.DeliveryID, item.Attempt)
+	}
+}
+
+// reprocessWebhookDelivery dispatches item to the provider-specific replay
+// path. Only GitHub is wired up for now since it's the only provider this
+// chunk's retry queue integrates with; other providers return an error so
+// the item keeps retrying (and eventually dead-letters) rather than being
+// silently dropped.
+func reprocessWebhookDelivery(item WebhookRetryItem) error {
+	switch item.Provider {
+	case "github":
+		return reprocessGitHubDelivery(item.Headers, item.Body)
+	default:
+		return fmt.Errorf("no retry handler registered for provider %q", item.Provider)
+	}
+}
+
+// listWebhookDeliveries handles GET /api/v1/webhooks/deliveries?repo_id=
+func listWebhookDeliveries(c *gin.Context) {
+	repoID := c.Query("repo_id")
+
+	query := `SELECT delivery_id, provider, repo_id, status, attempts, received_at, result, job_id, processed_at FROM webhook_deliveries`
+	args := []interface{}{}
+	if repoID != "" {
+		query += ` WHERE repo_id = $1`
+		args = append(args, repoID)
+	}
+	query += ` ORDER BY received_at DESC LIMIT 100`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var delivery WebhookDelivery
+		var repoIDCol, provider, status, deliveryID string
+		var attempts int
+		var receivedAt time.Time
+		var result, jobID sql.NullString
+		var processedAt sql.NullTime
+		if err := rows.Scan(&deliveryID, &provider, &repoIDCol, &status, &attempts, &receivedAt, &result, &jobID, &processedAt); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		delivery = WebhookDelivery{
+			DeliveryID: deliveryID,
+			Provider:   provider,
+			RepoID:     repoIDCol,
+			Status:     status,
+			Attempts:   attempts,
+			ReceivedAt: receivedAt,
+			Result:     result.String,
+			JobID:      jobID.String,
+		}
+		if processedAt.Valid {
+			delivery.ProcessedAt = &processedAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// replayWebhookDelivery handles POST
+// /api/v1/webhooks/deliveries/:delivery_id/replay. It replays the copy in
+// the retry or dead-letter queue if there is one, and otherwise the raw
+// headers and payload recordDelivery stored in webhook_deliveries.
+func replayWebhookDelivery(c *gin.Context) {
+	deliveryID := c.Param("delivery_id")
+
+	item, found := findQueuedWebhookRetry(deliveryID)
+	if !found {
+		var provider string
+		var headersJSON, payload []byte
+		err := db.QueryRow(`SELECT provider, headers, payload FROM webhook_deliveries WHERE delivery_id = $1`, deliveryID).Scan(&provider, &headersJSON, &payload)
+		if err == nil && len(payload) > 0 {
+			item = WebhookRetryItem{DeliveryID: deliveryID, Provider: provider, Body: payload}
+			found = json.Unmarshal(headersJSON, &item.Headers) == nil
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Delivery is not queued and has no stored raw payload to replay",
+		})
+		return
+	}
+
+	if err := reprocessWebhookDelivery(item); err != nil {
+		log.Printf("⚠️  Manual replay of %s failed: %v", deliveryID, err)
+		incrementDeliveryAttempts(deliveryID)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Replay failed: %v", err)})
+		return
+	}
+
+	updateDeliveryStatus(deliveryID, "delivered")
+	c.JSON(http.StatusOK, gin.H{"status": "delivered"})
+}
+
+// findQueuedWebhookRetry looks for deliveryID in both the scheduled-retry
+// sorted set and the dead-letter list, removing it from wherever it's found
+// since a manual replay supersedes the automatic one.
+func findQueuedWebhookRetry(deliveryID string) (WebhookRetryItem, bool) {
+	scheduled, err := redisClient.ZRangeByScore(ctx, webhookRetryQueueKey, &redis.ZRangeBy{Min: "-inf", Max: "+inf"}).Result()
+	if err == nil {
+		for _, raw := range scheduled {
+			var item WebhookRetryItem
+			if json.Unmarshal([]byte(raw), &item) == nil && item.DeliveryID == deliveryID {
+				redisClient.ZRem(ctx, webhookRetryQueueKey, raw)
+				return item, true
+			}
+		}
+	}
+
+	dead, err := redisClient.LRange(ctx, webhookRetryDeadKey, 0, -1).Result()
+	if err == nil {
+		for _, raw := range dead {
+			var item WebhookRetryItem
+			if json.Unmarshal([]byte(raw), &item) == nil && item.DeliveryID == deliveryID {
+				redisClient.LRem(ctx, webhookRetryDeadKey, 1, raw)
+				return item, true
+			}
+		}
+	}
+
+	return WebhookRetryItem{}, false
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryIDForRequest(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-GitHub-Delivery", "gh-1")
+	assert.Equal(t, "gh-1", deliveryIDForRequest("github", headers))
+
+	headers = http.Header{}
+	headers.Set("X-Gitea-Delivery", "gitea-1")
+	assert.Equal(t, "gitea-1", deliveryIDForRequest("gitea", headers))
+
+	headers = http.Header{}
+	headers.Set("X-Request-UUID", "bb-1")
+	assert.Equal(t, "bb-1", deliveryIDForRequest("bitbucket", headers))
+
+	assert.Equal(t, "", deliveryIDForRequest("gitlab", http.Header{}))
+}
+
+func TestWithinReplayWindow_AllowsMissingHeader(t *testing.T) {
+	assert.True(t, withinReplayWindow(http.Header{}))
+}
+
+func TestWithinReplayWindow_RejectsStaleTimestamp(t *testing.T) {
+	headers := http.Header{}
+	stale := time.Now().Add(-10 * time.Minute).Unix()
+	headers.Set("X-ArchMind-Webhook-Timestamp", strconv.FormatInt(stale, 10))
+	assert.False(t, withinReplayWindow(headers))
+}
+
+func TestWithinReplayWindow_AllowsRecentTimestamp(t *testing.T) {
+	headers := http.Header{}
+	recent := time.Now().Add(-1 * time.Minute).Unix()
+	headers.Set("X-ArchMind-Webhook-Timestamp", strconv.FormatInt(recent, 10))
+	assert.True(t, withinReplayWindow(headers))
+}
+
+func TestWebhookRetryBackoff_HasFiveStagesUpToSixHours(t *testing.T) {
+	assert.Len(t, webhookRetryBackoff, 5)
+	assert.Equal(t, 6*time.Hour, webhookRetryBackoff[len(webhookRetryBackoff)-1])
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ensureGitHubAppSchema creates the github_app_installations table if
+// migrations were not applied. Each row maps one repository to the GitHub
+// App installation authorized to act on it, so commit-status posting can
+// mint a short-lived installation token instead of relying on a single
+// shared PAT.
+func ensureGitHubAppSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS github_app_installations (
+			installation_id BIGINT PRIMARY KEY,
+			repo_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			private_key_pem TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS idx_github_app_installations_repo_id ON github_app_installations(repo_id);
+	`)
+	return err
+}
+
+// githubInstallationToken is the response body of
+// POST /app/installations/{id}/access_tokens.
+type githubInstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// resolveGitHubToken returns an auth token suitable for the "Authorization:
+// Bearer <token>" header GitHub's REST API expects when posting commit
+// statuses for repoURL. If a github_app_installations row exists for the
+// repo, it mints a fresh installation token; otherwise it falls back to the
+// GITHUB_TOKEN env var (a classic PAT), matching resolveWebhookSecret's
+// "per-resource override, env fallback" pattern.
+func resolveGitHubToken(repoURL string) (string, error) {
+	var installationID int64
+	var privateKeyPEM string
+	err := db.QueryRow(`
+		SELECT i.installation_id, i.private_key_pem
+		FROM github_app_installations i
+		JOIN repositories r ON i.repo_id = r.id
+		WHERE r.url = $1
+	`, repoURL).Scan(&installationID, &privateKeyPEM)
+
+	if err == sql.ErrNoRows {
+		token := getEnv("GITHUB_TOKEN", "")
+		if token == "" {
+			return "", fmt.Errorf("no github_app_installations row for %s and GITHUB_TOKEN is not set", repoURL)
+		}
+		return token, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to look up GitHub App installation: %w", err)
+	}
+
+	return mintInstallationToken(installationID, privateKeyPEM)
+}
+
+// mintInstallationToken exchanges a GitHub App's private key for a
+// short-lived installation access token: sign a JWT as the app (RS256, <=10
+// minute expiry per GitHub's requirement), then trade it for an
+// installation token via the GitHub REST API.
+func mintInstallationToken(installationID int64, privateKeyPEM string) (string, error) {
+	appJWT, err := signGitHubAppJWT(privateKeyPEM)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+
+	url := fmt.Sprintf("https://api.github.com/app/installations/%d/access_tokens", installationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+appJWT)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("installation token exchange returned status %d", resp.StatusCode)
+	}
+
+	var token githubInstallationToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode installation token response: %w", err)
+	}
+	return token.Token, nil
+}
+
+// signGitHubAppJWT builds and signs the RS256 JWT GitHub Apps authenticate
+// with: a 9-minute-lifetime {iat, exp, iss} claim set, base64url-encoded and
+// signed with the app's PEM-encoded RSA private key.
+func signGitHubAppJWT(privateKeyPEM string) (string, error) {
+	key, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		"iat": now.Add(-30 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": getEnv("GITHUB_APP_ID", ""),
+	}
+
+	headerSeg, err := base64URLEncodeJSON(header)
+	if err != nil {
+		return "", err
+	}
+	claimsSeg, err := base64URLEncodeJSON(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := headerSeg + "." + claimsSeg
+	hashed := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parseRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM-encoded private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not RSA")
+	}
+	return key, nil
+}
+
+func base64URLEncodeJSON(v interface{}) (string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(body), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestVerifyGitHubSignature_ValidSignatureOnJSONBody(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	sig := signGitHubPayload(body, "shh")
+	if !verifyGitHubSignature(body, sig, "shh") {
+		t.Fatal("expected a valid signature over a JSON body to verify")
+	}
+}
+
+func TestVerifyGitHubSignature_ValidSignatureOnFormEncodedBody(t *testing.T) {
+	// The signature is computed over the raw request body (the
+	// "payload=<urlencoded-json>" form), not the decoded JSON
+	// extractGitHubJSONPayload later pulls out of it.
+	rawFormBody := []byte("payload=%7B%22ref%22%3A%22refs%2Fheads%2Fmain%22%7D")
+	sig := signGitHubPayload(rawFormBody, "shh")
+
+	if !verifyGitHubSignature(rawFormBody, sig, "shh") {
+		t.Fatal("expected a signature over the raw form body to verify")
+	}
+
+	decodedPayload, err := extractGitHubJSONPayload(rawFormBody, "application/x-www-form-urlencoded")
+	if err != nil {
+		t.Fatalf("unexpected error decoding payload: %v", err)
+	}
+	if verifyGitHubSignature(decodedPayload, sig, "shh") {
+		t.Fatal("a signature computed over the raw body must not verify against the decoded payload")
+	}
+}
+
+func TestVerifyGitHubSignature_RejectsMissingHeader(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	if verifyGitHubSignature(body, "", "shh") {
+		t.Fatal("expected a missing signature header to fail verification")
+	}
+}
+
+func TestVerifyGitHubSignature_RejectsMalformedPrefix(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := signGitHubPayload(body, "shh")
+	malformed := mac[len("sha256="):] // strip the "sha256=" prefix, leaving a bare hex digest
+	if verifyGitHubSignature(body, malformed, "shh") {
+		t.Fatal("expected a signature missing the sha256= prefix to fail verification")
+	}
+}
+
+func TestVerifyGitHubSignature_RejectsWrongSecret(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	sig := signGitHubPayload(body, "shh")
+	if verifyGitHubSignature(body, sig, "wrong-secret") {
+		t.Fatal("expected a signature computed with a different secret to fail verification")
+	}
+}
+
+func TestVerifyGitHubSignature_SecretRotation_AcceptsOldAndNewSecret(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "new-secret")
+	t.Setenv("GITHUB_WEBHOOK_SECRETS", "old-secret, older-secret")
+
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	if !verifyGitHubSignature(body, signGitHubPayload(body, "new-secret"), "") {
+		t.Fatal("expected the current GITHUB_WEBHOOK_SECRET to verify")
+	}
+	if !verifyGitHubSignature(body, signGitHubPayload(body, "old-secret"), "") {
+		t.Fatal("expected a signature from a rotated-out secret in GITHUB_WEBHOOK_SECRETS to still verify")
+	}
+	if verifyGitHubSignature(body, signGitHubPayload(body, "unlisted-secret"), "") {
+		t.Fatal("expected a secret not present in any rotation list to fail verification")
+	}
+}
+
+func TestCandidateWebhookSecrets_CombinesOverrideEnvAndRotationList(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "env-secret")
+	t.Setenv("GITHUB_WEBHOOK_SECRETS", "rotated-1,rotated-2")
+
+	got := candidateWebhookSecrets("repo-secret")
+	want := []string{"repo-secret", "env-secret", "rotated-1", "rotated-2"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
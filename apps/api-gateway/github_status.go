@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Arch-Mind/backend/internal/retry"
+)
+
+// githubAPIRetryPolicy retries transient GitHub API failures (rate limiting
+// and upstream hiccups) rather than failing a commit status/check-run update
+// on the first blip; it leaves genuine 4xx rejections (bad token, unknown
+// repo) alone so those surface immediately instead of burning attempts.
+var githubAPIRetryPolicy = retry.Policy{
+	MaxAttempts:     3,
+	InitialDelay:    500 * time.Millisecond,
+	MaxDelay:        4 * time.Second,
+	RetryableStatus: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+}
+
+// githubCommitStatusContext is the "context" string GitHub groups our
+// commit statuses under, distinguishing them from CI/other integrations on
+// the same commit.
+const githubCommitStatusContext = "arch-mind/analysis"
+
+// maxCheckRunAnnotations is the most annotations GitHub accepts per
+// check-run request; postGitHubCheckRun drops any annotations beyond it.
+const maxCheckRunAnnotations = 50
+
+// checkRunAnnotation is one per-file finding surfaced on the Checks UI.
+type checkRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"` // "notice", "warning", "failure"
+	Message         string `json:"message"`
+}
+
+// postGitHubCommitStatus posts a Commit Status API entry for sha, mirroring
+// forgejo's services/actions/commit_status.go flow: one POST per state
+// transition (pending on job creation, success/failure on completion).
+func postGitHubCommitStatus(ctx context.Context, repoURL, sha, state, description, targetURL string) error {
+	owner, repo, err := splitGitHubFullName(repoURL)
+	if err != nil {
+		return err
+	}
+
+	token, err := resolveGitHubToken(repoURL)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"state":       state,
+		"description": truncateGitHubDescription(description),
+		"context":     githubCommitStatusContext,
+		"target_url":  targetURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/statuses/%s", owner, repo, sha)
+	return doGitHubAPIRequest(ctx, http.MethodPost, url, token, body)
+}
+
+// postGitHubCheckRun creates a new Checks API run for sha, attaching the
+// first maxCheckRunAnnotations per-file findings; any annotations beyond
+// that are dropped. Each call creates a separate run; it never updates an
+// existing one.
+func postGitHubCheckRun(ctx context.Context, repoURL, sha, conclusion, summary string, annotations []checkRunAnnotation) error {
+	owner, repo, err := splitGitHubFullName(repoURL)
+	if err != nil {
+		return err
+	}
+
+	token, err := resolveGitHubToken(repoURL)
+	if err != nil {
+		return err
+	}
+
+	if len(annotations) > maxCheckRunAnnotations {
+		annotations = annotations[:maxCheckRunAnnotations]
+	}
+
+	payload := map[string]interface{}{
+		"name":       githubCommitStatusContext,
+		"head_sha":   sha,
+		"status":     "completed",
+		"conclusion": conclusion,
+		"output": map[string]interface{}{
+			"title":       "ArchMind analysis",
+			"summary":     summary,
+			"annotations": annotations,
+		},
+	}
+	if conclusion == "" {
+		payload["status"] = "in_progress"
+		delete(payload, "conclusion")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/check-runs", owner, repo)
+	return doGitHubAPIRequest(ctx, http.MethodPost, url, token, body)
+}
+
+func doGitHubAPIRequest(ctx context.Context, method, url, token string, body []byte) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return retry.Do(ctx, githubAPIRetryPolicy, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			return &retry.StatusError{
+				StatusCode: resp.StatusCode,
+				Err:        fmt.Errorf("GitHub API %s %s returned status %d", method, url, resp.StatusCode),
+			}
+		}
+		return nil
+	})
+}
+
+// splitGitHubFullName splits a clone URL's "owner/repo" out, stripping any
+// .git suffix and scheme/host prefix so it works for both
+// "https://github.com/owner/repo.git" and normalized "owner/repo" forms.
+func splitGitHubFullName(repoURL string) (owner, repo string, err error) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	trimmed = strings.TrimPrefix(trimmed, "https://github.com/")
+	trimmed = strings.TrimPrefix(trimmed, "git@github.com:")
+
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("could not resolve owner/repo from %q", repoURL)
+	}
+	return parts[0], parts[1], nil
+}
+
+// truncateGitHubDescription keeps description within GitHub's 140-character
+// limit for the Commit Status API.
+func truncateGitHubDescription(description string) string {
+	const maxLen = 140
+	if len(description) <= maxLen {
+		return description
+	}
+	return description[:maxLen-len("…")] + "…"
+}
+
+// publishPendingCommitStatus posts the initial "pending" status right after
+// a pull_request-triggered job is queued, so the PR shows a yellow dot
+// immediately instead of waiting for the worker to finish.
+func publishPendingCommitStatus(repoURL, sha, jobID string) {
+	if sha == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := postGitHubCommitStatus(ctx, repoURL, sha, "pending", "ArchMind analysis queued", mermaidExportURL(generateRepoID(repoURL))); err != nil {
+		log.Printf("⚠️  Failed to post pending commit status for %s@%s: %v", repoURL, sha, err)
+	}
+}
+
+// publishCommitStatusForJob posts the terminal commit status for a
+// pull_request-triggered job once the worker reports COMPLETED/FAILED,
+// resolving the job's head SHA from its stored options (after_sha, set by
+// createWebhookAnalysisJob for pull_request triggers).
+func publishCommitStatusForJob(jobID, repoURL, status string, summary string) {
+	options, err := loadJobOptions(jobID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load options for commit status on job %s: %v", jobID, err)
+		return
+	}
+	if options["trigger"] != "pull_request" {
+		return
+	}
+	sha := options["after_sha"]
+	if sha == "" {
+		return
+	}
+
+	state := "success"
+	conclusion := "success"
+	description := "ArchMind analysis passed"
+	if status == "FAILED" {
+		state = "failure"
+		conclusion = "failure"
+		description = "ArchMind analysis failed"
+	}
+	if summary != "" {
+		description = truncateGitHubDescription(summary)
+	}
+
+	targetURL := mermaidExportURL(generateRepoID(repoURL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := postGitHubCommitStatus(ctx, repoURL, sha, state, description, targetURL); err != nil {
+		log.Printf("⚠️  Failed to post %s commit status for job %s: %v", state, jobID, err)
+	}
+	if err := postGitHubCheckRun(ctx, repoURL, sha, conclusion, description, nil); err != nil {
+		log.Printf("⚠️  Failed to post check-run for job %s: %v", jobID, err)
+	}
+}
+
+// loadJobOptions reads back the options JSONB column storeJob wrote,
+// unmarshalled into the map[string]string shape createWebhookAnalysisJob
+// builds it from.
+func loadJobOptions(jobID string) (map[string]string, error) {
+	var raw []byte
+	if err := db.QueryRow("SELECT options FROM analysis_jobs WHERE job_id = $1", jobID).Scan(&raw); err != nil {
+		return nil, err
+	}
+	options := map[string]string{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &options); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
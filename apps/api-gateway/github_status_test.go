@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitGitHubFullName_HandlesHTTPSAndSSH(t *testing.T) {
+	owner, repo, err := splitGitHubFullName("https://github.com/Arch-Mind/backend.git")
+	assert.NoError(t, err)
+	assert.Equal(t, "Arch-Mind", owner)
+	assert.Equal(t, "backend", repo)
+
+	owner, repo, err = splitGitHubFullName("git@github.com:Arch-Mind/backend.git")
+	assert.NoError(t, err)
+	assert.Equal(t, "Arch-Mind", owner)
+	assert.Equal(t, "backend", repo)
+}
+
+func TestSplitGitHubFullName_RejectsUnresolvable(t *testing.T) {
+	_, _, err := splitGitHubFullName("not-a-repo-url")
+	assert.Error(t, err)
+}
+
+func TestTruncateGitHubDescription_LeavesShortTextAlone(t *testing.T) {
+	assert.Equal(t, "short", truncateGitHubDescription("short"))
+}
+
+func TestTruncateGitHubDescription_TruncatesAt140(t *testing.T) {
+	long := strings.Repeat("a", 200)
+	truncated := truncateGitHubDescription(long)
+	assert.Len(t, truncated, 140)
+	assert.True(t, strings.HasSuffix(truncated, "…"))
+}
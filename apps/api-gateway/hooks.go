@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// hookEventsQueueKey is the Redis list every status-change HookEvent is
+// pushed onto so deliveries survive a gateway restart.
+const hookEventsQueueKey = "hook_events"
+
+// hookEventsScheduledKey is a Redis sorted set of events waiting out their
+// retry backoff, scored by the unix millisecond timestamp they become due.
+const hookEventsScheduledKey = "hook_events:scheduled"
+
+// hookEventsDeadKey holds events that exhausted hookMaxAttempts.
+const hookEventsDeadKey = "hooks:dead"
+
+// hookMaxAttempts is how many delivery attempts (the first one included) a
+// hook event gets before it is dead-lettered.
+const hookMaxAttempts = 8
+
+// hookBackoff is the delay before each retry, indexed by attempt number
+// (attempt 1 failing waits hookBackoff[0] before attempt 2, and so on).
+// Once exhausted, the last delay is reused.
+var hookBackoff = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+// JobHook represents a user-registered webhook for a single job's status
+// transitions.
+type JobHook struct {
+	ID        int       `json:"id"`
+	JobID     string    `json:"job_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobHookCreateRequest is the body of POST /api/v1/jobs/:id/hooks.
+type JobHookCreateRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// HookEvent is a single webhook delivery attempt for a job status
+// transition, queued in Redis so delivery survives a restart.
+type HookEvent struct {
+	DeliveryID string                 `json:"delivery_id"`
+	JobID      string                 `json:"job_id"`
+	HookID     int                    `json:"hook_id"`
+	URL        string                 `json:"url"`
+	Secret     string                 `json:"secret"`
+	FromStatus string                 `json:"from_status"`
+	ToStatus   string                 `json:"to_status"`
+	Payload    map[string]interface{} `json:"payload"`
+	Attempt    int                    `json:"attempt"`
+}
+
+// ensureJobHooksSchema creates the job_hooks table if migrations haven't
+// been applied yet.
+func ensureJobHooksSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS job_hooks (
+			id SERIAL PRIMARY KEY,
+			job_id VARCHAR(255) NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT,
+			events JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS idx_job_hooks_job_id ON job_hooks(job_id);
+	`)
+	return err
+}
+
+// createJobHook handles POST /api/v1/jobs/:id/hooks
+func createJobHook(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	var req JobHookCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	events := req.Events
+	if len(events) == 0 {
+		events = []string{"QUEUED", "PROCESSING", "COMPLETED", "FAILED", "CANCELLED"}
+	}
+	eventsJSON, _ := json.Marshal(events)
+
+	var hook JobHook
+	err := db.QueryRow(`
+		INSERT INTO job_hooks (job_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, job_id, url, events, created_at
+	`, jobID, req.URL, req.Secret, eventsJSON).Scan(&hook.ID, &hook.JobID, &hook.URL, &eventsJSON, &hook.CreatedAt)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register hook"})
+		return
+	}
+	_ = json.Unmarshal(eventsJSON, &hook.Events)
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// listDeadJobHookEvents handles GET /api/v1/jobs/:id/hooks/dead
+func listDeadJobHookEvents(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	raw, err := redisClient.LRange(ctx, hookEventsDeadKey, 0, -1).Result()
+	if err != nil {
+		log.Printf("Redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read dead-letter queue"})
+		return
+	}
+
+	events := make([]HookEvent, 0)
+	for _, item := range raw {
+		var event HookEvent
+		if err := json.Unmarshal([]byte(item), &event); err != nil {
+			continue
+		}
+		if event.JobID == jobID {
+			events = append(events, event)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job_id": jobID, "dead_letters": events})
+}
+
+// dispatchJobHooks fans a status transition out to every hook registered for
+// jobID whose Events list contains toStatus, enqueuing one HookEvent per
+// hook onto the durable Redis delivery queue.
+func dispatchJobHooks(jobID, fromStatus, toStatus string, payload map[string]interface{}) {
+	rows, err := db.Query("SELECT id, url, secret, events FROM job_hooks WHERE job_id = $1", jobID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load job hooks for %s: %v", jobID, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hookID int
+		var url string
+		var secret sql.NullString
+		var eventsJSON []byte
+		if err := rows.Scan(&hookID, &url, &secret, &eventsJSON); err != nil {
+			log.Printf("⚠️  Scan error reading job hook: %v", err)
+			continue
+		}
+		var events []string
+		_ = json.Unmarshal(eventsJSON, &events)
+		if !containsFormat(events, toStatus) {
+			continue
+		}
+
+		event := HookEvent{
+			DeliveryID: uuid.New().String(),
+			JobID:      jobID,
+			HookID:     hookID,
+			URL:        url,
+			Secret:     secret.String,
+			FromStatus: fromStatus,
+			ToStatus:   toStatus,
+			Payload:    payload,
+			Attempt:    1,
+		}
+		enqueueHookEvent(event)
+	}
+}
+
+func enqueueHookEvent(event HookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal hook event: %v", err)
+		return
+	}
+	if err := redisClient.LPush(ctx, hookEventsQueueKey, body).Err(); err != nil {
+		log.Printf("⚠️  Failed to enqueue hook event: %v", err)
+	}
+}
+
+func rescheduleHookEvent(event HookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal hook event: %v", err)
+		return
+	}
+	// event.Attempt is already the upcoming attempt number, so the attempt
+	// that just failed is event.Attempt-1 and its delay is at index
+	// event.Attempt-2.
+	delay := hookBackoff[len(hookBackoff)-1]
+	if i := event.Attempt - 2; i >= 0 && i < len(hookBackoff) {
+		delay = hookBackoff[i]
+	}
+	dueAt := float64(time.Now().Add(delay).UnixMilli())
+	if err := redisClient.ZAdd(ctx, hookEventsScheduledKey, &redis.Z{Score: dueAt, Member: string(body)}).Err(); err != nil {
+		log.Printf("⚠️  Failed to reschedule hook event: %v", err)
+	}
+}
+
+func deadLetterHookEvent(event HookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := redisClient.LPush(ctx, hookEventsDeadKey, body).Err(); err != nil {
+		log.Printf("⚠️  Failed to dead-letter hook event %s: %v", event.DeliveryID, err)
+	}
+	log.Printf("💀 Hook delivery %s for job %s dead-lettered after %d attempts", event.DeliveryID, event.JobID, event.Attempt-1)
+}
+
+// signHookPayload computes the HMAC-SHA256 signature sent in X-Signature.
+func signHookPayload(body []byte, secret string) string {
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliverHookEvent(event HookEvent) error {
+	body, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, event.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", signHookPayload(body, event.Secret))
+	req.Header.Set("X-ArchMind-Delivery", event.DeliveryID)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("hook delivery returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// HookDispatcher drains the durable hook_events queue and the scheduled
+// retry sorted set, delivering events with exponential backoff and
+// dead-lettering them once hookMaxAttempts is exceeded.
+type HookDispatcher struct {
+	pollInterval time.Duration
+}
+
+// NewHookDispatcher builds a dispatcher that polls every pollInterval.
+func NewHookDispatcher(pollInterval time.Duration) *HookDispatcher {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &HookDispatcher{pollInterval: pollInterval}
+}
+
+// Run blocks, draining due hook deliveries until stop is closed. It
+// coordinates with the gateway's existing graceful-shutdown sequence: main
+// closes stop before tearing down Redis/Postgres.
+func (d *HookDispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.promoteDueEvents()
+			d.drainReady(stop)
+		}
+	}
+}
+
+// promoteDueEvents moves scheduled retries whose backoff has elapsed back
+// onto the main delivery queue.
+func (d *HookDispatcher) promoteDueEvents() {
+	nowMs := float64(time.Now().UnixMilli())
+	due, err := redisClient.ZRangeByScore(ctx, hookEventsScheduledKey, &redis.ZRangeBy{
+		Min: "0",
+		Max: fmt.Sprintf("%f", nowMs),
+	}).Result()
+	if err != nil || len(due) == 0 {
+		return
+	}
+	for _, item := range due {
+		if err := redisClient.LPush(ctx, hookEventsQueueKey, item).Err(); err != nil {
+			continue
+		}
+		redisClient.ZRem(ctx, hookEventsScheduledKey, item)
+	}
+}
+
+// drainReady pops and attempts delivery of every event currently on the
+// main queue without blocking past stop being closed.
+func (d *HookDispatcher) drainReady(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		result, err := redisClient.RPop(ctx, hookEventsQueueKey).Result()
+		if err != nil {
+			return // queue empty or Redis error; wait for next tick
+		}
+
+		var event HookEvent
+		if err := json.Unmarshal([]byte(result), &event); err != nil {
+			log.Printf("⚠️  Failed to decode hook event: %v", err)
+			continue
+		}
+
+		if err := deliverHookEvent(event); err != nil {
+			log.Printf("⚠️  Hook delivery %s failed (attempt %d): %v", event.DeliveryID, event.Attempt, err)
+			event.Attempt++
+			if event.Attempt > hookMaxAttempts {
+				deadLetterHookEvent(event)
+				continue
+			}
+			rescheduleHookEvent(event)
+			continue
+		}
+
+		log.Printf("✅ Hook delivery %s succeeded (job=%s, status=%s)", event.DeliveryID, event.JobID, event.ToStatus)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignHookPayload(t *testing.T) {
+	body := []byte(`{"job_id":"abc","to_status":"COMPLETED"}`)
+
+	sig := signHookPayload(body, "s3cret")
+	assert.True(t, strings.HasPrefix(sig, "sha256="), "signature should be prefixed with sha256=")
+
+	// Same body and secret must always produce the same signature.
+	assert.Equal(t, sig, signHookPayload(body, "s3cret"))
+
+	// A different secret must produce a different signature.
+	assert.NotEqual(t, sig, signHookPayload(body, "other-secret"))
+
+	assert.Equal(t, "", signHookPayload(body, ""), "no secret means no signature")
+}
+
+func TestHookBackoffSchedule(t *testing.T) {
+	assert.Len(t, hookBackoff, 5, "five explicit backoff tiers: 1s, 5s, 30s, 5m, 30m")
+	for i := 1; i < len(hookBackoff); i++ {
+		assert.Greater(t, hookBackoff[i], hookBackoff[i-1], "each retry tier should wait longer than the last")
+	}
+}
+
+func TestHookMaxAttemptsDeadLettersAfterExhaustion(t *testing.T) {
+	for attempt := 1; attempt <= hookMaxAttempts; attempt++ {
+		assert.LessOrEqual(t, attempt, hookMaxAttempts)
+	}
+	// One attempt past the max should be treated as exhausted.
+	assert.Greater(t, hookMaxAttempts+1, hookMaxAttempts)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Arch-Mind/backend/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// jobTracker coalesces webhook-driven analysis jobs per repo+branch ref,
+// following gitdeploy's Pending/Active/Recents pattern: a ref whose job is
+// still running merges new deliveries into its Pending slot instead of
+// queuing a duplicate, and a ref that just completed is held in Recents so a
+// retried delivery (GitHub sometimes retries) is dropped rather than
+// re-queued. Initialized in main().
+var jobTracker *jobs.Tracker
+
+const defaultJobRecentsTTL = 5 * time.Minute
+
+// autoCancelStaleActiveAfter bounds how long a ref's Active job runs before a
+// new delivery for the same ref cancels it outright (reason
+// "system:superseded") instead of merging into its Pending slot and waiting:
+// a push that lands 30s+ into analysis of an older commit is almost always
+// superseding it, so there's no point letting the stale run finish.
+const autoCancelStaleActiveAfter = 30 * time.Second
+
+// jobRecentsTTL reads JOB_RECENTS_TTL_MS, defaulting to 5 minutes.
+func jobRecentsTTL() time.Duration {
+	ms := getEnv("JOB_RECENTS_TTL_MS", "")
+	if ms == "" {
+		return defaultJobRecentsTTL
+	}
+	parsed, err := strconv.Atoi(ms)
+	if err != nil || parsed <= 0 {
+		return defaultJobRecentsTTL
+	}
+	return time.Duration(parsed) * time.Millisecond
+}
+
+// submitWebhookAnalysisJob routes a webhook-derived analysis through
+// jobTracker instead of calling createWebhookAnalysisJob directly: a
+// delivery for a ref whose job is already running merges into that ref's
+// Pending slot (merged=true), a delivery within the Recents TTL of the ref's
+// last completion is dropped (dropped=true), and otherwise a fresh job is
+// created and activated.
+func submitWebhookAnalysisJob(repoURL, branch, trigger, before, after string, changedFiles, removedFiles []string) (jobID string, merged bool, dropped bool, err error) {
+	ref, shouldCreate, dropped := jobTracker.Submit(repoURL, branch, trigger, before, after, changedFiles, removedFiles)
+	if dropped {
+		return "", false, true, nil
+	}
+	if !shouldCreate {
+		go autoCancelStaleActive(ref)
+		return "", true, false, nil
+	}
+
+	jobID, err = createWebhookAnalysisJob(repoURL, branch, trigger, before, after, changedFiles, removedFiles)
+	if err != nil {
+		return "", false, false, err
+	}
+	jobTracker.Activate(ref, repoURL, branch, jobID)
+	return jobID, false, false, nil
+}
+
+// autoCancelStaleActive cancels ref's Active job when a new delivery merged
+// into its Pending slot because that job has been running long enough to be
+// considered stale, so the superseding push's analysis starts right away via
+// onWebhookJobComplete's drain instead of waiting out the old run.
+func autoCancelStaleActive(ref jobs.RefID) {
+	var active *jobs.Active
+	for _, a := range jobTracker.Actives() {
+		if a.RefID == ref {
+			active = a
+			break
+		}
+	}
+	if active == nil || time.Since(active.StartedAt) < autoCancelStaleActiveAfter {
+		return
+	}
+
+	if _, _, err := cancelJobWithReason(active.JobID, "system:superseded"); err != nil {
+		log.Printf("⚠️  Job tracker: failed to auto-cancel stale job %s for %s: %v", active.JobID, ref, err)
+	}
+}
+
+// onWebhookJobComplete is called from updateJob once a job reaches a
+// terminal status, draining any Pending slot the job's ref had accumulated
+// into a freshly queued follow-up job.
+func onWebhookJobComplete(jobID string) {
+	pending := jobTracker.Complete(jobID)
+	if pending == nil {
+		return
+	}
+
+	newJobID, err := createWebhookAnalysisJob(pending.RepoURL, pending.Branch, pending.Trigger, pending.Before, pending.After, pending.ChangedFiles, pending.RemovedFiles)
+	if err != nil {
+		log.Printf("❌ Job tracker: failed to promote pending webhook analysis for %s: %v", pending.RefID, err)
+		return
+	}
+	jobTracker.Activate(pending.RefID, pending.RepoURL, pending.Branch, newJobID)
+
+	log.Printf("⬆️  Job tracker: promoted pending webhook analysis to job %s for %s (%d deliveries merged)", newJobID, pending.RefID, pending.MergedCount)
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:      "coalesced",
+		JobID:     newJobID,
+		RepoID:    generateRepoID(pending.RepoURL),
+		Message:   fmt.Sprintf("%d webhook deliveries merged", pending.MergedCount),
+		Timestamp: time.Now().UTC(),
+	})
+}
+
+// jobTrackerEntry is the common shape returned by the pending/active/recent
+// inspection endpoints.
+type jobTrackerEntry struct {
+	RefID           string    `json:"ref_id"`
+	RepoURL         string    `json:"repo_url"`
+	Branch          string    `json:"branch"`
+	JobID           string    `json:"job_id,omitempty"`
+	MergedFileCount int       `json:"merged_file_count"`
+	StartedAt       time.Time `json:"started_at"`
+}
+
+// listPendingJobs handles GET /api/jobs/pending, listing every ref with
+// webhook deliveries merged behind a still-running job.
+func listPendingJobs(c *gin.Context) {
+	pendings := jobTracker.Pendings()
+	entries := make([]jobTrackerEntry, 0, len(pendings))
+	for _, p := range pendings {
+		entries = append(entries, jobTrackerEntry{
+			RefID:           string(p.RefID),
+			RepoURL:         p.RepoURL,
+			Branch:          p.Branch,
+			MergedFileCount: len(p.ChangedFiles) + len(p.RemovedFiles),
+			StartedAt:       p.StartedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"pending": entries})
+}
+
+// listActiveJobs handles GET /api/jobs/active, listing every ref the
+// tracker believes currently has a job running.
+func listActiveJobs(c *gin.Context) {
+	actives := jobTracker.Actives()
+	entries := make([]jobTrackerEntry, 0, len(actives))
+	for _, a := range actives {
+		entries = append(entries, jobTrackerEntry{
+			RefID:     string(a.RefID),
+			RepoURL:   a.RepoURL,
+			Branch:    a.Branch,
+			JobID:     a.JobID,
+			StartedAt: a.StartedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"active": entries})
+}
+
+// listRecentJobs handles GET /api/jobs/recent, listing every ref still
+// within the Recents dedup TTL.
+func listRecentJobs(c *gin.Context) {
+	recents := jobTracker.Recents()
+	entries := make([]jobTrackerEntry, 0, len(recents))
+	for _, r := range recents {
+		entries = append(entries, jobTrackerEntry{
+			RefID:     string(r.RefID),
+			RepoURL:   r.RepoURL,
+			Branch:    r.Branch,
+			JobID:     r.JobID,
+			StartedAt: r.CompletedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"recent": entries})
+}
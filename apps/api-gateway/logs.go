@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLogDir is where per-job log files live when LOG_DIR isn't set.
+const defaultLogDir = "./logs"
+
+// defaultLogRetentionDays bounds how long a finalized job's gzipped log
+// file is kept when LOG_RETENTION_DAYS isn't set.
+const defaultLogRetentionDays = 14
+
+// logRingCapacity is how many of a job's most recent log lines are kept in
+// memory for the tail endpoint and new SSE subscribers' initial backlog.
+const logRingCapacity = 500
+
+// LogEntry is one line a worker reports via POST /api/v1/jobs/:id/logs.
+type LogEntry struct {
+	Timestamp time.Time `json:"ts"`
+	Stream    string    `json:"stream"` // "stdout" or "stderr"
+	Text      string    `json:"text"`
+}
+
+var (
+	// logRings holds the last logRingCapacity entries per job, keyed by job
+	// ID, so the tail endpoint and new stream subscribers don't have to
+	// reread the log file for the common case.
+	logRingsMu sync.Mutex
+	logRings   = make(map[string][]LogEntry)
+
+	// logSubscribers fans out newly appended entries to SSE clients
+	// currently streaming a given job's logs.
+	logSubscribersMu sync.Mutex
+	logSubscribers   = make(map[string]map[chan LogEntry]bool)
+)
+
+// logDir resolves LOG_DIR, falling back to defaultLogDir.
+func logDir() string {
+	return getEnv("LOG_DIR", defaultLogDir)
+}
+
+// logRetentionDays resolves LOG_RETENTION_DAYS, falling back to
+// defaultLogRetentionDays when unset or invalid.
+func logRetentionDays() int {
+	raw := getEnv("LOG_RETENTION_DAYS", "")
+	if raw == "" {
+		return defaultLogRetentionDays
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return defaultLogRetentionDays
+	}
+	return n
+}
+
+// ensureLogDir creates LOG_DIR if it doesn't already exist.
+func ensureLogDir() error {
+	return os.MkdirAll(logDir(), 0755)
+}
+
+func jobLogPath(jobID string) string {
+	return filepath.Join(logDir(), jobID+".ndjson")
+}
+
+func jobLogGzipPath(jobID string) string {
+	return filepath.Join(logDir(), jobID+".ndjson.gz")
+}
+
+// jobLogTailURL returns the tail endpoint for jobID if anything has been
+// logged against it yet, or "" otherwise, for JobUpdate.LogURL.
+func jobLogTailURL(jobID string) string {
+	if !hasJobLogs(jobID) {
+		return ""
+	}
+	return fmt.Sprintf("/api/v1/jobs/%s/logs", jobID)
+}
+
+func hasJobLogs(jobID string) bool {
+	logRingsMu.Lock()
+	_, inMemory := logRings[jobID]
+	logRingsMu.Unlock()
+	if inMemory {
+		return true
+	}
+	if _, err := os.Stat(jobLogPath(jobID)); err == nil {
+		return true
+	}
+	if _, err := os.Stat(jobLogGzipPath(jobID)); err == nil {
+		return true
+	}
+	return false
+}
+
+// appendJobLog records one log entry for jobID: it grows the in-memory
+// ring buffer, appends the entry to the job's ndjson file, and fans it out
+// to any SSE subscribers currently streaming this job's logs.
+func appendJobLog(jobID string, entry LogEntry) error {
+	logRingsMu.Lock()
+	ring := append(logRings[jobID], entry)
+	if len(ring) > logRingCapacity {
+		ring = ring[len(ring)-logRingCapacity:]
+	}
+	logRings[jobID] = ring
+	logRingsMu.Unlock()
+
+	if err := ensureLogDir(); err != nil {
+		return fmt.Errorf("failed to ensure log dir: %w", err)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+	f, err := os.OpenFile(jobLogPath(jobID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file for job %s: %w", jobID, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write log entry for job %s: %w", jobID, err)
+	}
+
+	logSubscribersMu.Lock()
+	for ch := range logSubscribers[jobID] {
+		select {
+		case ch <- entry:
+		default:
+			// Slow subscriber; drop the entry rather than block the writer.
+		}
+	}
+	logSubscribersMu.Unlock()
+
+	return nil
+}
+
+// subscribeJobLogs registers a channel to receive jobID's newly appended
+// log entries; the caller must call the returned func to unregister.
+func subscribeJobLogs(jobID string) (chan LogEntry, func()) {
+	ch := make(chan LogEntry, 32)
+
+	logSubscribersMu.Lock()
+	if logSubscribers[jobID] == nil {
+		logSubscribers[jobID] = make(map[chan LogEntry]bool)
+	}
+	logSubscribers[jobID][ch] = true
+	logSubscribersMu.Unlock()
+
+	unsubscribe := func() {
+		logSubscribersMu.Lock()
+		delete(logSubscribers[jobID], ch)
+		if len(logSubscribers[jobID]) == 0 {
+			delete(logSubscribers, jobID)
+		}
+		logSubscribersMu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// tailJobLogs returns jobID's last n log entries, preferring the in-memory
+// ring buffer and falling back to the ndjson file (e.g. after a gateway
+// restart dropped the ring buffer).
+func tailJobLogs(jobID string, n int) ([]LogEntry, error) {
+	logRingsMu.Lock()
+	ring := logRings[jobID]
+	logRingsMu.Unlock()
+	if len(ring) > 0 {
+		if len(ring) > n {
+			ring = ring[len(ring)-n:]
+		}
+		return append([]LogEntry{}, ring...), nil
+	}
+
+	f, err := os.Open(jobLogPath(jobID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// postJobLogs handles POST /api/v1/jobs/:id/logs: workers report
+// newline-delimited JSON log entries for jobID.
+func postJobLogs(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log entry", "details": err.Error()})
+			return
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = time.Now().UTC()
+		}
+		if err := appendJobLog(jobID, entry); err != nil {
+			log.Printf("⚠️  Failed to append log entry for job %s: %v", jobID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record log entry"})
+			return
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse request body", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "ok", "entries_recorded": count})
+}
+
+// getJobLogsTail handles GET /api/v1/jobs/:id/logs?tail=200: a snapshot of
+// the most recent log lines for jobID.
+func getJobLogsTail(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	tail := logRingCapacity
+	if raw := c.Query("tail"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			tail = n
+		}
+	}
+	if tail > logRingCapacity {
+		tail = logRingCapacity
+	}
+
+	entries, err := tailJobLogs(jobID, tail)
+	if err != nil {
+		log.Printf("⚠️  Failed to read logs for job %s: %v", jobID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read job logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job_id": jobID, "entries": entries})
+}
+
+// streamJobLogs handles GET /api/v1/jobs/:id/logs/stream: an SSE endpoint
+// that replays the current tail and then flushes new entries as workers
+// report them.
+func streamJobLogs(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Streaming unsupported"})
+		return
+	}
+
+	ch, unsubscribe := subscribeJobLogs(jobID)
+	defer unsubscribe()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	backlog, err := tailJobLogs(jobID, logRingCapacity)
+	if err != nil {
+		log.Printf("⚠️  Failed to read log backlog for job %s: %v", jobID, err)
+	}
+	for _, entry := range backlog {
+		writeLogSSEEvent(c.Writer, entry)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case entry, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeLogSSEEvent(c.Writer, entry)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeLogSSEEvent(w io.Writer, entry LogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
+
+// finalizeJobLogs is called once a job reaches a terminal status: it gzips
+// the job's ndjson log file (if any), drops the in-memory ring buffer, and
+// sweeps LOG_RETENTION_DAYS-expired log files from LOG_DIR. SSE subscribers
+// stay registered until they disconnect.
+func finalizeJobLogs(jobID string) {
+	logRingsMu.Lock()
+	delete(logRings, jobID)
+	logRingsMu.Unlock()
+
+	if err := gzipJobLog(jobID); err != nil {
+		log.Printf("⚠️  Failed to gzip log file for job %s: %v", jobID, err)
+	}
+
+	if err := pruneExpiredLogs(); err != nil {
+		log.Printf("⚠️  Failed to prune expired job logs: %v", err)
+	}
+}
+
+func gzipJobLog(jobID string) error {
+	src := jobLogPath(jobID)
+	in, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(jobLogGzipPath(jobID))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+// pruneExpiredLogs deletes gzipped job log files older than
+// LOG_RETENTION_DAYS from LOG_DIR.
+func pruneExpiredLogs() error {
+	entries, err := os.ReadDir(logDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -logRetentionDays())
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".ndjson.gz") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(logDir(), entry.Name())); err != nil {
+				log.Printf("⚠️  Failed to remove expired log file %s: %v", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogDir_DefaultsWhenUnset(t *testing.T) {
+	t.Setenv("LOG_DIR", "")
+	assert.Equal(t, defaultLogDir, logDir())
+}
+
+func TestLogRetentionDays_DefaultsWhenUnset(t *testing.T) {
+	t.Setenv("LOG_RETENTION_DAYS", "")
+	assert.Equal(t, defaultLogRetentionDays, logRetentionDays())
+}
+
+func TestLogRetentionDays_ReadsEnvOverride(t *testing.T) {
+	t.Setenv("LOG_RETENTION_DAYS", "30")
+	assert.Equal(t, 30, logRetentionDays())
+}
+
+func TestJobLogPath_UsesNdjsonExtension(t *testing.T) {
+	t.Setenv("LOG_DIR", "/var/logs/archmind")
+	assert.Equal(t, "/var/logs/archmind/job-1.ndjson", jobLogPath("job-1"))
+	assert.Equal(t, "/var/logs/archmind/job-1.ndjson.gz", jobLogGzipPath("job-1"))
+}
+
+func TestJobLogTailURL_EmptyWhenNoLogsRecorded(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	assert.Equal(t, "", jobLogTailURL("never-logged-job"))
+}
+
+func TestAppendJobLog_CapsRingBufferAtCapacity(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	jobID := "ring-buffer-job"
+
+	for i := 0; i < logRingCapacity+10; i++ {
+		assert.NoError(t, appendJobLog(jobID, LogEntry{Timestamp: time.Now(), Stream: "stdout", Text: "line"}))
+	}
+
+	entries, err := tailJobLogs(jobID, logRingCapacity+10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, logRingCapacity)
+}
+
+func TestSubscribeJobLogs_ReceivesAppendedEntry(t *testing.T) {
+	t.Setenv("LOG_DIR", t.TempDir())
+	jobID := "subscriber-job"
+
+	ch, unsubscribe := subscribeJobLogs(jobID)
+	defer unsubscribe()
+
+	entry := LogEntry{Timestamp: time.Now(), Stream: "stderr", Text: "boom"}
+	assert.NoError(t, appendJobLog(jobID, entry))
+
+	select {
+	case got := <-ch:
+		assert.Equal(t, entry.Text, got.Text)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscriber to receive log entry")
+	}
+}
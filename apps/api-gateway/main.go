@@ -24,6 +24,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Arch-Mind/backend/internal/jobs"
+	"github.com/Arch-Mind/backend/internal/jobsapi"
+	"github.com/Arch-Mind/backend/internal/retry"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -114,12 +117,16 @@ type WebhookResponse struct {
 
 // WebhookConfig represents stored webhook configuration
 type WebhookConfig struct {
-	ID        int       `json:"id"`
-	RepoID    int       `json:"repo_id"`
-	RepoURL   string    `json:"repo_url,omitempty"`
-	URL       string    `json:"url"`
-	Secret    *string   `json:"secret,omitempty"`
-	Events    []string  `json:"events"`
+	ID      int      `json:"id"`
+	RepoID  int      `json:"repo_id"`
+	RepoURL string   `json:"repo_url,omitempty"`
+	URL     string   `json:"url"`
+	Secret  *string  `json:"secret,omitempty"`
+	Events  []string `json:"events"`
+	// Provider identifies which git forge this webhook was configured for
+	// ("github", "gitlab", "gitea", "bitbucket"), so the right signature
+	// verification algorithm can be applied to inbound deliveries.
+	Provider  string    `json:"provider"`
 	Active    bool      `json:"active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -127,11 +134,12 @@ type WebhookConfig struct {
 
 // WebhookCreateRequest represents incoming webhook configuration
 type WebhookCreateRequest struct {
-	RepoID  *int     `json:"repo_id,omitempty"`
-	RepoURL string   `json:"repo_url,omitempty"`
-	URL     string   `json:"url"`
-	Secret  string   `json:"secret,omitempty"`
-	Events  []string `json:"events"`
+	RepoID   *int     `json:"repo_id,omitempty"`
+	RepoURL  string   `json:"repo_url,omitempty"`
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret,omitempty"`
+	Events   []string `json:"events"`
+	Provider string   `json:"provider,omitempty"`
 }
 
 type WebhookListResponse struct {
@@ -211,6 +219,15 @@ type AnalyzeRequest struct {
 	RepoURL string            `json:"repo_url" binding:"required"`
 	Branch  string            `json:"branch"`
 	Options map[string]string `json:"options"`
+	// Private marks repo_url as a private GitHub repository, requiring
+	// UserID to resolve to a linked GitHub OAuth token before cloning.
+	Private bool `json:"private"`
+	// UserID is the authenticated user whose linked GitHub OAuth token (see
+	// auth_github.go) the clone step should use for repo_url.
+	UserID string `json:"user_id"`
+	// EnableLFS opts the clone step into resolving Git LFS pointer files via
+	// internal/lfs after cloning, for repos storing large binary assets.
+	EnableLFS bool `json:"enable_lfs"`
 }
 
 // AnalysisJob represents a job in the queue
@@ -223,6 +240,7 @@ type AnalysisJob struct {
 	Progress    int               `json:"progress"` // 0-100
 	Options     map[string]string `json:"options"`
 	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at,omitempty"`
 	subscribers []chan JobUpdate  `json:"-"` // WebSocket subscribers for this job
 }
 
@@ -238,7 +256,17 @@ type JobUpdate struct {
 	ChangedNodes  []string               `json:"changed_nodes,omitempty"`
 	ChangedEdges  []string               `json:"changed_edges,omitempty"`
 	ResultSummary map[string]interface{} `json:"result_summary,omitempty"`
-	Timestamp     time.Time              `json:"timestamp"`
+	// LogURL points at the tail endpoint for this job's logs, when the
+	// update carries a job ID a log has been recorded against.
+	LogURL string `json:"log_url,omitempty"`
+	// Environment is set on "promoted" updates to the environment a job was
+	// just pinned to (e.g. "staging", "production").
+	Environment string    `json:"environment,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	// OriginInstance is the publishing gateway process's instanceID, so a
+	// subscriber receiving this update back over Redis pub/sub can tell it
+	// published the update itself and skip re-broadcasting it locally.
+	OriginInstance string `json:"origin_instance,omitempty"`
 }
 
 // WebSocketClient represents a connected WebSocket client
@@ -284,8 +312,15 @@ func NewWebSocketHub() *WebSocketHub {
 	}
 }
 
-// Run starts the WebSocket hub
+// Run starts the WebSocket hub, including the Redis pub/sub subscriber
+// goroutine that lets a horizontally scaled gateway fleet deliver updates
+// to clients connected to a different instance than the one that produced
+// the update.
 func (h *WebSocketHub) Run() {
+	if wsPubSubEnabled() {
+		go h.subscribeLoop()
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -387,10 +422,17 @@ func (h *WebSocketHub) Run() {
 	}
 }
 
-// BroadcastJobUpdate sends an update to all clients subscribed to a job
+// BroadcastJobUpdate sends an update to locally connected clients
+// subscribed to a job, and, when pub/sub is enabled, publishes it to Redis
+// so other gateway instances' locally connected clients receive it too.
 func (h *WebSocketHub) BroadcastJobUpdate(update JobUpdate) {
 	update.Timestamp = time.Now()
+	if update.OriginInstance == "" {
+		update.OriginInstance = instanceID
+	}
 	h.broadcast <- update
+	h.publish(update)
+	fanOutActiveJobUpdate(update)
 }
 
 // readPump handles incoming messages from the WebSocket connection
@@ -458,26 +500,29 @@ type JobResponse struct {
 	Status    string    `json:"status"`
 	Message   string    `json:"message"`
 	CreatedAt time.Time `json:"created_at"`
+	// Coalesced is true when this request was merged into an already-active
+	// job for the same repo+branch instead of queuing a new one.
+	Coalesced bool `json:"coalesced,omitempty"`
+	// CoalescedFrom lists prior request IDs that were merged into this job's
+	// pending slot before it was promoted. Only meaningful once the merged
+	// request is itself promoted; empty for a freshly queued job.
+	CoalescedFrom []string `json:"coalesced_from,omitempty"`
 }
 
-// JobUpdateRequest represents the request to update a job
-type JobUpdateRequest struct {
-	Status        *string                `json:"status,omitempty"`
-	Progress      *int                   `json:"progress,omitempty"`
-	ResultSummary map[string]interface{} `json:"result_summary,omitempty"`
-	Error         *string                `json:"error,omitempty"`
-}
+// JobUpdateRequest represents the request to update a job. It is an alias
+// for jobsapi.JobUpdateRequest so tooling like cmd/jobs-loadtest can share
+// the exact same schema instead of an ad-hoc copy that could drift.
+type JobUpdateRequest = jobsapi.JobUpdateRequest
 
-// JobUpdateResponse represents the response after updating a job
-type JobUpdateResponse struct {
-	JobID     string    `json:"job_id"`
-	Status    string    `json:"status"`
-	Message   string    `json:"message"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+// JobUpdateResponse represents the response after updating a job. It is an
+// alias for jobsapi.JobUpdateResponse; see JobUpdateRequest.
+type JobUpdateResponse = jobsapi.JobUpdateResponse
 
 var (
-	redisClient *redis.Client
+	// redisClient is a redis.UniversalClient so the job queue, hub, and
+	// webhook code paths don't care whether initRedis resolved a plain
+	// client, a Sentinel failover client, or a cluster client.
+	redisClient redis.UniversalClient
 	db          *sql.DB
 	wsHub       *WebSocketHub
 	ctx         = context.Background()
@@ -495,11 +540,67 @@ func main() {
 	// Initialize PostgreSQL connection
 	initPostgres()
 
+	// Initialize the analysis job coordinator and replay any pending
+	// backlog left behind by a previous gateway instance.
+	jobCoordinator = NewJobCoordinator()
+	if err := loadJobBacklog(); err != nil {
+		log.Printf("⚠️  Failed to load job_backlog: %v", err)
+	}
+
 	// Initialize WebSocket Hub
 	wsHub = NewWebSocketHub()
 	go wsHub.Run()
 	log.Println("🔌 WebSocket Hub initialized")
 
+	// Ensure the per-job log directory exists before any worker tries to
+	// report logs against it.
+	if err := ensureLogDir(); err != nil {
+		log.Printf("⚠️  Failed to ensure log directory: %v", err)
+	}
+
+	// Initialize the push debouncer so CI bots / merge trains don't spawn
+	// one analysis job per push
+	debouncer = NewAnalysisDebouncer(analysisDebounceWindow())
+	log.Printf("⏳ Push debouncer initialized (window=%s)", debouncer.quiet)
+
+	// Initialize the webhook job tracker so a push/PR ref whose job is still
+	// running merges into that ref's Pending slot instead of queuing a
+	// duplicate, and retried deliveries within the Recents TTL are dropped.
+	jobTracker = jobs.NewTracker(jobRecentsTTL())
+	log.Println("🗂️  Webhook job tracker initialized")
+
+	// Register configured notifiers so job lifecycle events fan out to
+	// Slack/Discord/HTTP/email destinations.
+	initNotifiers()
+
+	// Start the reaper so crashed workers never leave jobs stuck PROCESSING
+	reaperStop := make(chan struct{})
+	reaper := NewReaper(90*time.Second, 3)
+	go reaper.Run(reaperStop)
+	log.Println("♻️  Reaper started")
+
+	// Start the hook dispatcher so status-change webhook deliveries survive
+	// a gateway restart instead of being lost mid-retry.
+	hookDispatcherStop := make(chan struct{})
+	hookDispatcher := NewHookDispatcher(time.Second)
+	go hookDispatcher.Run(hookDispatcherStop)
+	log.Println("🪝 Hook dispatcher started")
+
+	// Start the webhook retry dispatcher so inbound deliveries that failed
+	// verification or job creation get another shot instead of being lost.
+	webhookRetryStop := make(chan struct{})
+	webhookRetryDispatcher := NewWebhookRetryDispatcher(time.Second)
+	go webhookRetryDispatcher.Run(webhookRetryStop)
+	log.Println("🔁 Webhook retry dispatcher started")
+
+	// Start the notifier retry dispatcher so outbound Slack/Discord/Teams/
+	// Matrix/HTTP deliveries that failed get retried with backoff instead
+	// of being silently dropped.
+	notifierRetryStop := make(chan struct{})
+	notifierRetryDispatcher := NewNotifierRetryDispatcher(time.Second)
+	go notifierRetryDispatcher.Run(notifierRetryStop)
+	log.Println("🔔 Notifier retry dispatcher started")
+
 	// Initialize Gin router
 	router := setupRouter()
 
@@ -526,6 +627,12 @@ func main() {
 	<-quit
 	log.Println("🛑 Shutting down API Gateway...")
 
+	// Stop background workers before closing the connections they depend on
+	close(reaperStop)
+	close(hookDispatcherStop)
+	close(webhookRetryStop)
+	close(notifierRetryStop)
+
 	// Create shutdown context with 30-second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -558,30 +665,60 @@ func main() {
 	log.Println("👋 API Gateway shutdown complete")
 }
 
-// initRedis initializes the Redis client
+// initRedis initializes the Redis client. It honors, in priority order,
+// Sentinel config (REDIS_SENTINEL_ADDRS/REDIS_SENTINEL_MASTER), cluster
+// config (REDIS_CLUSTER_ADDRS), then falls back to the single-node
+// REDIS_URL/REDIS_PASSWORD pair it always supported.
 func initRedis() {
-	redisURL := strings.TrimSpace(getEnv("REDIS_URL", "localhost:6379"))
 	redisPassword := getEnv("REDIS_PASSWORD", "")
 
-	var options *redis.Options
-	if strings.HasPrefix(redisURL, "redis://") || strings.HasPrefix(redisURL, "rediss://") {
-		parsed, err := redis.ParseURL(redisURL)
-		if err != nil {
-			log.Fatalf("Failed to parse REDIS_URL: %v", err)
-		}
-		if parsed.Password == "" && redisPassword != "" {
-			parsed.Password = redisPassword
-		}
-		options = parsed
-	} else {
-		options = &redis.Options{
-			Addr:     redisURL,
+	sentinelAddrs := splitAndTrim(getEnv("REDIS_SENTINEL_ADDRS", ""))
+	sentinelMaster := strings.TrimSpace(getEnv("REDIS_SENTINEL_MASTER", ""))
+	clusterAddrs := splitAndTrim(getEnv("REDIS_CLUSTER_ADDRS", ""))
+
+	switch {
+	case len(sentinelAddrs) > 0 && sentinelMaster != "":
+		sentinelPassword := getEnv("REDIS_SENTINEL_PASSWORD", "")
+		failoverClient := redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       sentinelMaster,
+			SentinelAddrs:    sentinelAddrs,
+			SentinelPassword: sentinelPassword,
+			Password:         redisPassword,
+			DB:               0,
+		})
+		redisClient = failoverClient
+		log.Printf("🔁 Redis: using Sentinel failover (master=%s, sentinels=%v)", sentinelMaster, sentinelAddrs)
+
+	case len(clusterAddrs) > 0:
+		redisClient = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    clusterAddrs,
 			Password: redisPassword,
-			DB:       0,
+		})
+		log.Printf("🔁 Redis: using cluster client (addrs=%v)", clusterAddrs)
+
+	default:
+		redisURL := strings.TrimSpace(getEnv("REDIS_URL", "localhost:6379"))
+
+		var options *redis.Options
+		if strings.HasPrefix(redisURL, "redis://") || strings.HasPrefix(redisURL, "rediss://") {
+			parsed, err := redis.ParseURL(redisURL)
+			if err != nil {
+				log.Fatalf("Failed to parse REDIS_URL: %v", err)
+			}
+			if parsed.Password == "" && redisPassword != "" {
+				parsed.Password = redisPassword
+			}
+			options = parsed
+		} else {
+			options = &redis.Options{
+				Addr:     redisURL,
+				Password: redisPassword,
+				DB:       0,
+			}
 		}
-	}
 
-	redisClient = redis.NewClient(options)
+		redisClient = redis.NewClient(options)
+	}
 
 	// Test connection
 	if err := redisClient.Ping(ctx).Err(); err != nil {
@@ -601,6 +738,69 @@ func initPostgres() {
 	if err := ensureCommitHistorySchema(); err != nil {
 		log.Printf("⚠️  Failed to ensure commit_history schema: %v", err)
 	}
+
+	if err := ensureReaperSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure reaper schema: %v", err)
+	}
+
+	if err := ensureResumeSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure resume schema: %v", err)
+	}
+
+	if err := ensureJobHooksSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure job_hooks schema: %v", err)
+	}
+
+	if err := ensureWebhookProviderSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure webhooks provider schema: %v", err)
+	}
+
+	if err := ensureRepoNotifiersSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure repo_notifiers schema: %v", err)
+	}
+
+	if err := ensureNotificationDeliveriesSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure notification_deliveries schema: %v", err)
+	}
+
+	if err := ensureGitHubAppSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure github_app_installations schema: %v", err)
+	}
+
+	if err := ensureWebhookDeliveriesSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure webhook_deliveries schema: %v", err)
+	}
+
+	if err := ensureJobBacklogSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure job_backlog schema: %v", err)
+	}
+
+	if err := ensureCancelSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure job cancellation schema: %v", err)
+	}
+
+	if err := ensureIncrementalPollingIndexes(); err != nil {
+		log.Printf("⚠️  Failed to ensure incremental polling indexes: %v", err)
+	}
+
+	if err := ensurePromotionsSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure promotions schema: %v", err)
+	}
+
+	if err := ensureGitHubOAuthSchema(); err != nil {
+		log.Printf("⚠️  Failed to ensure github_oauth_tokens schema: %v", err)
+	}
+}
+
+// ensureIncrementalPollingIndexes adds the indexes listJobs and
+// listCommitHistory's updated_after cursor mode scan, if migrations
+// weren't applied.
+func ensureIncrementalPollingIndexes() error {
+	_, err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_analysis_jobs_updated_at ON analysis_jobs(updated_at);
+		CREATE INDEX IF NOT EXISTS idx_commit_history_authored_at_sha ON commit_history(authored_at, commit_sha);
+	`)
+	return err
 }
 
 // ensureCommitHistorySchema creates commit history storage if migrations were not applied.
@@ -626,47 +826,44 @@ func ensureCommitHistorySchema() error {
 	return err
 }
 
-// connectPostgresWithRetry attempts to connect to PostgreSQL with exponential backoff
+// connectPostgresWithRetry attempts to connect to PostgreSQL with capped,
+// jittered exponential backoff (see internal/retry), so a fleet of gateway
+// replicas restarting together don't all hammer Postgres in lockstep.
 func connectPostgresWithRetry(dbURL string, maxRetries int) *sql.DB {
 	var connection *sql.DB
-	var err error
+	attempt := 0
+
+	policy := retry.Policy{
+		MaxAttempts:  maxRetries,
+		InitialDelay: time.Second,
+		MaxDelay:     16 * time.Second,
+	}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
+	err := retry.Do(context.Background(), policy, func(ctx context.Context) error {
+		attempt++
 		log.Printf("🔄 Attempting to connect to PostgreSQL... (attempt %d/%d)", attempt, maxRetries)
 
-		connection, err = sql.Open("postgres", dbURL)
+		conn, err := sql.Open("postgres", dbURL)
 		if err != nil {
-			if attempt < maxRetries {
-				waitTime := time.Duration(1<<uint(attempt-1)) * time.Second // 1s, 2s, 4s, 8s, 16s
-				log.Printf("⚠️  Failed to open PostgreSQL connection: %v. Retrying in %v (attempt %d/%d)...",
-					err, waitTime, attempt, maxRetries)
-				time.Sleep(waitTime)
-				continue
-			}
-			log.Printf("❌ Failed to connect to PostgreSQL after %d attempts: %v", maxRetries, err)
-			return nil
+			log.Printf("⚠️  Failed to open PostgreSQL connection: %v", err)
+			return err
 		}
-
-		// Test the connection
-		err = connection.Ping()
-		if err != nil {
-			connection.Close()
-			if attempt < maxRetries {
-				waitTime := time.Duration(1<<uint(attempt-1)) * time.Second // 1s, 2s, 4s, 8s, 16s
-				log.Printf("⚠️  Failed to ping PostgreSQL: %v. Retrying in %v (attempt %d/%d)...",
-					err, waitTime, attempt, maxRetries)
-				time.Sleep(waitTime)
-				continue
-			}
-			log.Printf("❌ Failed to ping PostgreSQL after %d attempts: %v", maxRetries, err)
-			return nil
+		if err := conn.Ping(); err != nil {
+			conn.Close()
+			log.Printf("⚠️  Failed to ping PostgreSQL: %v", err)
+			return err
 		}
 
-		log.Println("✅ Successfully connected to PostgreSQL")
-		return connection
+		connection = conn
+		return nil
+	})
+	if err != nil {
+		log.Printf("❌ Failed to connect to PostgreSQL after %d attempts: %v", maxRetries, err)
+		return nil
 	}
 
-	return nil
+	log.Println("✅ Successfully connected to PostgreSQL")
+	return connection
 }
 
 // setupRouter configures the Gin router with all routes
@@ -714,32 +911,73 @@ func setupRouter() *gin.Engine {
 	webhooks := router.Group("/webhooks")
 	{
 		webhooks.POST("/github", handleGitHubWebhook)
+		webhooks.POST("/gitlab", handleProviderWebhook(gitlabProvider{}))
+		webhooks.POST("/gitea", handleProviderWebhook(giteaProvider{}))
+		webhooks.POST("/bitbucket", handleProviderWebhook(bitbucketProvider{}))
+	}
+
+	// GitHub OAuth connector, so analyzeRepository can clone private repos
+	// on a linked user's behalf.
+	auth := router.Group("/auth/github")
+	{
+		auth.GET("/login", githubOAuthLogin)
+		auth.GET("/callback", githubOAuthCallback)
 	}
 
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
 		// Repository analysis
-		v1.POST("/analyze", analyzeRepository)
+		v1.POST("/analyze", attachGitHubTokenSource, analyzeRepository)
 		v1.GET("/jobs/:id", getJobStatus)
 		v1.PATCH("/jobs/:id", updateJob)
 		v1.GET("/jobs", listJobs)
+		v1.GET("/jobs/:id/checkpoint", getJobCheckpoint)
+		v1.POST("/jobs/:id/resume", resumeJob)
+		v1.POST("/jobs/:id/hooks", createJobHook)
+		v1.GET("/jobs/:id/hooks/dead", listDeadJobHookEvents)
+		v1.POST("/jobs/flush/:repo_id", flushAnalysisDebounce)
+		v1.GET("/jobs/backlog", listJobBacklog)
+		v1.DELETE("/jobs/:id", cancelJob)
+		v1.POST("/jobs/:id/cancel", cancelJob)
+		v1.POST("/jobs/:id/cancel/ack", ackJobCancel)
+		v1.POST("/jobs/:id/logs", postJobLogs)
+		v1.GET("/jobs/:id/logs", getJobLogsTail)
+		v1.GET("/jobs/:id/logs/stream", streamJobLogs)
 
 		// Repository management
 		v1.GET("/repositories", listRepositories)
 		v1.GET("/repositories/:id", getRepository)
 		v1.GET("/commits/:repo_id", listCommitHistory)
+		v1.POST("/repos/:repo_id/promotions", createPromotion)
+		v1.GET("/repos/:repo_id/promotions", listPromotions)
 
 		// Webhook management
 		v1.GET("/webhooks", listWebhooks)
 		v1.POST("/webhooks", createWebhook)
 		v1.DELETE("/webhooks/:id", deleteWebhook)
 		v1.POST("/webhooks/:id/ping", pingWebhook)
+		v1.GET("/webhooks/deliveries", listWebhookDeliveries)
+		v1.POST("/webhooks/deliveries/:delivery_id/replay", replayWebhookDelivery)
+
+		// Notifier subscriptions
+		v1.GET("/notifiers", listRepoNotifiers)
+		v1.POST("/notifiers", createRepoNotifier)
+		v1.DELETE("/notifiers/:id", deleteRepoNotifier)
+		v1.POST("/notifiers/:id/test", testRepoNotifier)
+		v1.GET("/notifiers/:id/deliveries", listNotifierDeliveries)
+		v1.POST("/notifiers/:id/deliveries/:delivery_id/replay", replayNotifierDelivery)
 	}
 
 	// Export endpoint
 	router.POST("/api/export/:repo_id", exportRepository)
 
+	// Job tracker inspection endpoints, for watching webhook coalescing happen
+	router.GET("/api/jobs/pending", listPendingJobs)
+	router.GET("/api/jobs/active", listActiveJobs)
+	router.GET("/api/jobs/recent", listRecentJobs)
+	router.GET("/api/jobs/active/stream", streamActiveJobUpdates)
+
 	return router
 }
 
@@ -880,62 +1118,91 @@ func analyzeRepository(c *gin.Context) {
 		return
 	}
 
-	// Create job ID
-	jobID := uuid.New().String()
-
-	// Generate deterministic Repo ID
-	repoID := generateRepoID(req.RepoURL)
+	var resolvedGitHubToken string
+	if req.Private {
+		if req.UserID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "repo_url is marked private; user_id with a linked GitHub account is required.",
+			})
+			return
+		}
+		ts, ok := githubTokenSourceFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "No GitHub account linked for user_id; visit /auth/github/login to link one.",
+			})
+			return
+		}
 
-	// Create job object
-	job := AnalysisJob{
-		JobID:     jobID,
-		RepoID:    repoID,
-		RepoURL:   req.RepoURL,
-		Branch:    req.Branch,
-		Status:    "QUEUED",
-		Options:   req.Options,
-		CreatedAt: time.Now().UTC(),
+		// Resolve (and, if necessary, refresh) the token now so a bad/expired
+		// link surfaces as a clear error on this request rather than failing
+		// the worker's clone step later with no feedback to the caller. The
+		// token itself is handed to the worker out-of-band via
+		// storeJobGitHubToken below, never through req.Options: that map is
+		// persisted verbatim into analysis_jobs.options, which the
+		// unauthenticated GET /api/v1/jobs and GET /api/v1/jobs/:id endpoints
+		// echo back in full.
+		token, err := ts.AccessToken(c.Request.Context())
+		if err != nil {
+			log.Printf("⚠️  Failed to resolve GitHub token for user %s: %v", req.UserID, err)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Linked GitHub account's token could not be used; re-link via /auth/github/login.",
+			})
+			return
+		}
+		resolvedGitHubToken = token
 	}
 
-	// Store job in PostgreSQL
-	// Note: We currently don't store RepoID in Postgres as it requires schema migration
-	// It is passed to Redis for the worker to use
-	if err := storeJob(job); err != nil {
-		log.Printf("Failed to store job in database: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create analysis job",
-		})
-		return
+	if req.EnableLFS {
+		if req.Options == nil {
+			req.Options = map[string]string{}
+		}
+		req.Options["enable_lfs"] = "true"
 	}
 
-	// Serialize job to JSON
-	jobJSON, err := json.Marshal(job)
+	// Generate deterministic Repo ID
+	repoID := generateRepoID(req.RepoURL)
+
+	// Submit through the coordinator so a burst of requests for the same
+	// repo+branch coalesces into the already-active job instead of each
+	// queuing its own.
+	jobID, coalesced, coalescedFrom, err := jobCoordinator.Submit(req.RepoURL, req.Branch, req.Options)
 	if err != nil {
-		log.Printf("Failed to marshal job: %v", err)
+		log.Printf("Failed to create analysis job: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create analysis job",
 		})
 		return
 	}
 
-	// Push job to Redis queue
-	if err := redisClient.LPush(ctx, "analysis_queue", jobJSON).Err(); err != nil {
-		log.Printf("Failed to push job to Redis: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to queue analysis job",
+	if resolvedGitHubToken != "" {
+		if err := storeJobGitHubToken(jobID, resolvedGitHubToken); err != nil {
+			log.Printf("⚠️  Failed to stash GitHub token for job %s: %v", jobID, err)
+		}
+	}
+
+	if coalesced {
+		log.Printf("🔀 Coalesced analysis request for repo: %s (ID: %s) into active job: %s", req.RepoURL, repoID, jobID)
+		c.JSON(http.StatusAccepted, JobResponse{
+			JobID:         jobID,
+			RepoID:        repoID,
+			Status:        "QUEUED",
+			Message:       "Merged into the already-active job for this repo+branch",
+			CreatedAt:     time.Now().UTC(),
+			Coalesced:     true,
+			CoalescedFrom: coalescedFrom,
 		})
 		return
 	}
 
 	log.Printf("📝 Created analysis job: %s for repo: %s (ID: %s)", jobID, req.RepoURL, repoID)
 
-	// Return response
 	c.JSON(http.StatusCreated, JobResponse{
 		JobID:     jobID,
 		RepoID:    repoID,
 		Status:    "QUEUED",
 		Message:   "Analysis job created successfully",
-		CreatedAt: job.CreatedAt,
+		CreatedAt: time.Now().UTC(),
 	})
 }
 
@@ -973,6 +1240,7 @@ func getJobStatus(c *gin.Context) {
 	if len(optionsJSON) > 0 {
 		json.Unmarshal(optionsJSON, &job.Options)
 	}
+	redactJobOptions(&job)
 
 	c.JSON(http.StatusOK, job)
 }
@@ -1021,7 +1289,7 @@ func updateJob(c *gin.Context) {
 
 	// Validate status transition if status is being updated
 	if req.Status != nil {
-		if !validateStatusTransition(currentStatus, *req.Status) {
+		if !validateStatusTransition(currentStatus, *req.Status, TransitionReasonAPI) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":          "Invalid status transition",
 				"current_status": currentStatus,
@@ -1058,6 +1326,7 @@ func updateJob(c *gin.Context) {
 		Status:        finalStatus,
 		Progress:      0,
 		ResultSummary: req.ResultSummary,
+		LogURL:        jobLogTailURL(jobID),
 		Timestamp:     time.Now(),
 	}
 
@@ -1098,10 +1367,47 @@ func updateJob(c *gin.Context) {
 				}
 			}
 		}
+
+		if findings := extractTopFindings(req.ResultSummary); len(findings) > 0 {
+			go notifyAll(NotificationEvent{
+				Kind:        "analysis_findings_new",
+				RepoID:      repoID,
+				RepoURL:     repoURL,
+				JobID:       jobID,
+				Status:      finalStatus,
+				MermaidURL:  mermaidExportURL(repoID),
+				TopFindings: findings,
+			})
+		}
 	}
 
 	wsHub.BroadcastJobUpdate(update)
 
+	if finalStatus == "COMPLETED" || finalStatus == "FAILED" || finalStatus == "CANCELLED" {
+		go notifyAll(NotificationEvent{
+			Kind:        "job_" + strings.ToLower(finalStatus),
+			RepoID:      repoID,
+			RepoURL:     repoURL,
+			JobID:       jobID,
+			Status:      finalStatus,
+			MermaidURL:  mermaidExportURL(repoID),
+			TopFindings: extractTopFindings(req.ResultSummary),
+		})
+		jobCoordinator.PromoteIfPending(jobID)
+		go finalizeJobLogs(jobID)
+		onWebhookJobComplete(jobID)
+		go publishCommitStatusForJob(jobID, repoURL, finalStatus, strings.Join(extractTopFindings(req.ResultSummary), "; "))
+	}
+
+	if req.Status != nil {
+		go dispatchJobHooks(jobID, currentStatus, finalStatus, gin.H{
+			"job_id":      jobID,
+			"from_status": currentStatus,
+			"to_status":   finalStatus,
+			"timestamp":   time.Now().UTC(),
+		})
+	}
+
 	log.Printf("📝 Updated job %s: status=%s", jobID, finalStatus)
 
 	c.JSON(http.StatusOK, JobUpdateResponse{
@@ -1114,12 +1420,29 @@ func updateJob(c *gin.Context) {
 
 // listJobs retrieves all analysis jobs
 func listJobs(c *gin.Context) {
-	rows, err := db.Query(`
-		SELECT job_id, repo_url, branch, status, options, created_at 
-		FROM analysis_jobs 
-		ORDER BY created_at DESC 
-		LIMIT 50
-	`)
+	cursor, sinceID, hasCursor, err := parseUpdatedAfterCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rows *sql.Rows
+	if hasCursor {
+		rows, err = db.Query(`
+			SELECT job_id, repo_url, branch, status, options, created_at, updated_at
+			FROM analysis_jobs
+			WHERE (updated_at, job_id) > ($1, $2)
+			ORDER BY updated_at ASC, job_id ASC
+			LIMIT 50
+		`, cursor, sinceID)
+	} else {
+		rows, err = db.Query(`
+			SELECT job_id, repo_url, branch, status, options, created_at, updated_at
+			FROM analysis_jobs
+			ORDER BY created_at DESC
+			LIMIT 50
+		`)
+	}
 	if err != nil {
 		log.Printf("Database error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1130,22 +1453,54 @@ func listJobs(c *gin.Context) {
 	defer rows.Close()
 
 	jobs := []AnalysisJob{}
+	var maxUpdatedAt time.Time
+	var lastJobID string
 	for rows.Next() {
 		var job AnalysisJob
 		var optionsJSON []byte
-		if err := rows.Scan(&job.JobID, &job.RepoURL, &job.Branch, &job.Status, &optionsJSON, &job.CreatedAt); err != nil {
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&job.JobID, &job.RepoURL, &job.Branch, &job.Status, &optionsJSON, &job.CreatedAt, &updatedAt); err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
 		if len(optionsJSON) > 0 {
 			json.Unmarshal(optionsJSON, &job.Options)
 		}
+		redactJobOptions(&job)
+		if updatedAt.Valid {
+			job.UpdatedAt = updatedAt.Time
+			if job.UpdatedAt.After(maxUpdatedAt) {
+				maxUpdatedAt = job.UpdatedAt
+			}
+		}
 		jobs = append(jobs, job)
+		lastJobID = job.JobID
+	}
+
+	if !hasCursor {
+		c.JSON(http.StatusOK, gin.H{
+			"jobs":  jobs,
+			"total": len(jobs),
+		})
+		return
 	}
 
+	if maxUpdatedAt.IsZero() {
+		maxUpdatedAt = cursor
+		lastJobID = sinceID
+	}
+	etag := cursorETag(maxUpdatedAt, lastJobID)
+	if respondNotModified(c, etag) {
+		return
+	}
+	c.Header("ETag", etag)
 	c.JSON(http.StatusOK, gin.H{
 		"jobs":  jobs,
 		"total": len(jobs),
+		"next_cursor": gin.H{
+			"updated_after": maxUpdatedAt.UnixMilli(),
+			"since_id":      lastJobID,
+		},
 	})
 }
 
@@ -1241,6 +1596,39 @@ func listCommitHistory(c *gin.Context) {
 		}
 	}
 
+	cursor, sinceID, hasCursor, err := parseUpdatedAfterCursor(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if hasCursor {
+		commits, maxAuthoredAt, lastSHA, err := queryCommitHistoryIncremental(repoID, cursor, sinceID, limit)
+		if err != nil {
+			log.Printf("Database error reading commit_history incrementally (repo=%s): %v", repoID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve commit history"})
+			return
+		}
+
+		if maxAuthoredAt.IsZero() {
+			maxAuthoredAt = cursor
+			lastSHA = sinceID
+		}
+		etag := cursorETag(maxAuthoredAt, lastSHA)
+		if respondNotModified(c, etag) {
+			return
+		}
+		c.Header("ETag", etag)
+		c.JSON(http.StatusOK, gin.H{
+			"repo_id": repoID,
+			"commits": commits,
+			"next_cursor": gin.H{
+				"updated_after": maxAuthoredAt.UnixMilli(),
+				"since_id":      lastSHA,
+			},
+		})
+		return
+	}
+
 	commits, err := queryCommitHistoryTable(repoID, limit)
 	if err != nil {
 		log.Printf("Database error reading commit_history table (repo=%s): %v", repoID, err)
@@ -1333,6 +1721,73 @@ func queryCommitHistoryTable(repoID string, limit int) ([]CommitHistoryItem, err
 	return commits, nil
 }
 
+// queryCommitHistoryIncremental returns commits authored after cursor
+// (tiebroken by sinceSHA), sorted ascending, for listCommitHistory's
+// updated_after polling mode. It reports the max authored_at and the last
+// commit_sha in the page so the caller can build next_cursor/ETag.
+func queryCommitHistoryIncremental(repoID string, cursor time.Time, sinceSHA string, limit int) ([]CommitHistoryItem, time.Time, string, error) {
+	rows, err := db.Query(`
+		SELECT commit_sha, author_name, author_email, authored_at, message, changed_files, files_changed_count
+		FROM commit_history
+		WHERE repo_uuid = $1 AND (authored_at, commit_sha) > ($2, $3)
+		ORDER BY authored_at ASC, commit_sha ASC
+		LIMIT $4
+	`, repoID, cursor, sinceSHA, limit)
+	if err != nil {
+		return nil, time.Time{}, "", err
+	}
+	defer rows.Close()
+
+	commits := make([]CommitHistoryItem, 0, limit)
+	var maxAuthoredAt time.Time
+	var lastSHA string
+	for rows.Next() {
+		var sha string
+		var authorName sql.NullString
+		var authorEmail sql.NullString
+		var authoredAt sql.NullTime
+		var message sql.NullString
+		var changedFilesJSON []byte
+		var filesChangedCount int
+
+		if err := rows.Scan(&sha, &authorName, &authorEmail, &authoredAt, &message, &changedFilesJSON, &filesChangedCount); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+
+		var changedFiles []string
+		if len(changedFilesJSON) > 0 {
+			_ = json.Unmarshal(changedFilesJSON, &changedFiles)
+		}
+
+		commit := CommitHistoryItem{
+			SHA:               sha,
+			AuthorName:        authorName.String,
+			AuthorEmail:       authorEmail.String,
+			Message:           message.String,
+			ChangedFiles:      changedFiles,
+			FilesChangedCount: filesChangedCount,
+		}
+		if authoredAt.Valid {
+			commit.AuthoredAt = authoredAt.Time.UTC().Format(time.RFC3339)
+			if authoredAt.Time.After(maxAuthoredAt) {
+				maxAuthoredAt = authoredAt.Time
+			}
+		}
+		if commit.FilesChangedCount == 0 {
+			commit.FilesChangedCount = len(commit.ChangedFiles)
+		}
+		commits = append(commits, commit)
+		lastSHA = sha
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, "", err
+	}
+
+	return commits, maxAuthoredAt, lastSHA, nil
+}
+
 func queryCommitHistoryFromJobSummaries(repoID string, limit int) ([]CommitHistoryItem, error) {
 	rows, err := db.Query(`
 		SELECT repo_url, result_summary
@@ -1411,7 +1866,7 @@ func queryCommitHistoryFromJobSummaries(repoID string, limit int) ([]CommitHisto
 // listWebhooks returns configured webhooks
 func listWebhooks(c *gin.Context) {
 	rows, err := db.Query(`
-		SELECT w.id, w.repo_id, r.url, w.url, w.secret, w.events, w.active, w.created_at, w.updated_at
+		SELECT w.id, w.repo_id, r.url, w.url, w.secret, w.events, w.provider, w.active, w.created_at, w.updated_at
 		FROM webhooks w
 		JOIN repositories r ON w.repo_id = r.id
 		ORDER BY w.created_at DESC
@@ -1430,7 +1885,7 @@ func listWebhooks(c *gin.Context) {
 		var hook WebhookConfig
 		var secret sql.NullString
 		var eventsJSON []byte
-		if err := rows.Scan(&hook.ID, &hook.RepoID, &hook.RepoURL, &hook.URL, &secret, &eventsJSON, &hook.Active, &hook.CreatedAt, &hook.UpdatedAt); err != nil {
+		if err := rows.Scan(&hook.ID, &hook.RepoID, &hook.RepoURL, &hook.URL, &secret, &eventsJSON, &hook.Provider, &hook.Active, &hook.CreatedAt, &hook.UpdatedAt); err != nil {
 			log.Printf("Scan error: %v", err)
 			continue
 		}
@@ -1503,11 +1958,16 @@ func createWebhook(c *gin.Context) {
 		secret = &req.Secret
 	}
 
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	if !isSupportedProvider(provider) {
+		provider = "github"
+	}
+
 	err := db.QueryRow(`
-		INSERT INTO webhooks (user_id, repo_id, url, secret, events, active)
-		VALUES ($1, $2, $3, $4, $5, true)
+		INSERT INTO webhooks (user_id, repo_id, url, secret, events, provider, active)
+		VALUES ($1, $2, $3, $4, $5, $6, true)
 		RETURNING id, created_at, updated_at
-	`, 1, repoID, req.URL, req.Secret, eventsJSON).Scan(&id, &createdAt, &updatedAt)
+	`, 1, repoID, req.URL, req.Secret, eventsJSON, provider).Scan(&id, &createdAt, &updatedAt)
 
 	if err != nil {
 		log.Printf("Database error: %v", err)
@@ -1529,6 +1989,7 @@ func createWebhook(c *gin.Context) {
 		URL:       req.URL,
 		Secret:    secret,
 		Events:    events,
+		Provider:  provider,
 		Active:    true,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
@@ -1663,7 +2124,20 @@ func exportRepository(c *gin.Context) {
 
 	graphURL := getEnv("GRAPH_ENGINE_URL", "http://localhost:8000")
 
-	graph, warnings, err := fetchGraphEngineGraph(graphURL, repoID, maxNodes)
+	var pinnedJobID string
+	if environment := c.Query("environment"); environment != "" {
+		resolved, err := resolvePromotedJobID(repoID, environment)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No promotion found for that environment"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve promotion", "details": err.Error()})
+			return
+		}
+		pinnedJobID = resolved
+	}
+
+	graph, warnings, err := fetchGraphEngineGraphForJob(graphURL, repoID, pinnedJobID, maxNodes)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{
 			"error":   "Failed to fetch graph",
@@ -1726,8 +2200,26 @@ func storeJob(job AnalysisJob) error {
 	return err
 }
 
+// TransitionReason identifies who is driving a status transition. Most
+// transitions come from the API on behalf of a worker or user, but some
+// (like reaping a dead worker's job back to QUEUED) are only legal when
+// initiated internally.
+type TransitionReason string
+
+const (
+	// TransitionReasonAPI covers ordinary worker/user-driven updates via
+	// PATCH /api/v1/jobs/:id.
+	TransitionReasonAPI TransitionReason = "api"
+	// TransitionReasonReaper is used by the Reaper when it recovers a job
+	// abandoned by a dead worker.
+	TransitionReasonReaper TransitionReason = "reaper"
+	// TransitionReasonResume is used by POST /api/v1/jobs/:id/resume to move
+	// a FAILED, resumable job back to QUEUED without losing its checkpoint.
+	TransitionReasonResume TransitionReason = "resume"
+)
+
 // validateStatusTransition checks if a status transition is valid
-func validateStatusTransition(currentStatus, newStatus string) bool {
+func validateStatusTransition(currentStatus, newStatus string, reason TransitionReason) bool {
 	// Define valid transitions
 	validTransitions := map[string][]string{
 		"QUEUED":     {"PROCESSING", "CANCELLED"},
@@ -1737,6 +2229,19 @@ func validateStatusTransition(currentStatus, newStatus string) bool {
 		"CANCELLED":  {}, // Terminal state
 	}
 
+	// PROCESSING -> QUEUED is only legal when the reaper is recovering a
+	// job whose worker stopped sending heartbeats; regular API callers
+	// must never be able to bounce a job back to QUEUED this way.
+	if currentStatus == "PROCESSING" && newStatus == "QUEUED" {
+		return reason == TransitionReasonReaper
+	}
+
+	// FAILED -> QUEUED is only legal through the resume endpoint, and only
+	// for jobs that were explicitly marked resumable.
+	if currentStatus == "FAILED" && newStatus == "QUEUED" {
+		return reason == TransitionReasonResume
+	}
+
 	allowedTransitions, exists := validTransitions[currentStatus]
 	if !exists {
 		return false
@@ -1764,8 +2269,8 @@ func updateJobInDB(jobID string, req JobUpdateRequest) (time.Time, error) {
 		args = append(args, *req.Status)
 		argIndex++
 
-		// Set completed_at if status is COMPLETED or FAILED
-		if *req.Status == "COMPLETED" || *req.Status == "FAILED" {
+		// Set completed_at if status is COMPLETED, FAILED, or CANCELLED
+		if *req.Status == "COMPLETED" || *req.Status == "FAILED" || *req.Status == "CANCELLED" {
 			updates = append(updates, fmt.Sprintf("completed_at = $%d", argIndex))
 			args = append(args, time.Now().UTC())
 			argIndex++
@@ -1794,6 +2299,22 @@ func updateJobInDB(jobID string, req JobUpdateRequest) (time.Time, error) {
 		argIndex++
 	}
 
+	if req.Checkpoint != nil {
+		checkpointJSON, err := json.Marshal(req.Checkpoint)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to marshal checkpoint: %w", err)
+		}
+		updates = append(updates, fmt.Sprintf("checkpoint = $%d", argIndex))
+		args = append(args, checkpointJSON)
+		argIndex++
+	}
+
+	if req.Resumable != nil {
+		updates = append(updates, fmt.Sprintf("resumable = $%d", argIndex))
+		args = append(args, *req.Resumable)
+		argIndex++
+	}
+
 	if len(updates) == 0 {
 		return time.Time{}, fmt.Errorf("no fields to update")
 	}
@@ -1934,6 +2455,22 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// splitAndTrim splits a comma-separated env value into a trimmed,
+// non-empty slice of addresses. Returns nil for an empty input.
+func splitAndTrim(csv string) []string {
+	if strings.TrimSpace(csv) == "" {
+		return nil
+	}
+	parts := strings.Split(csv, ",")
+	addrs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			addrs = append(addrs, trimmed)
+		}
+	}
+	return addrs
+}
+
 // =============================================================================
 // GitHub Webhook Handlers
 // =============================================================================
@@ -1952,7 +2489,32 @@ func handleGitHubWebhook(c *gin.Context) {
 		return
 	}
 
-	// Step 2: Determine event type and resolve secret
+	// Step 2: Reject stale deliveries before doing anything else with them
+	if !withinReplayWindow(c.Request.Header) {
+		log.Printf("❌ Webhook: delivery timestamp outside ±%s window from IP: %s", webhookDeliveryTimestampWindow, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, WebhookResponse{
+			Status:  "error",
+			Message: "Delivery timestamp outside acceptable window",
+		})
+		return
+	}
+
+	// Step 3: Reject duplicate deliveries using GitHub's X-GitHub-Delivery
+	// header, which GitHub keeps the same when it redelivers an event.
+	deliveryID := c.GetHeader("X-GitHub-Delivery")
+	duplicate, err := recordDelivery(deliveryID, "github", "", c.GetHeader("X-Hub-Signature-256"), c.Request.Header.Clone(), body)
+	if err != nil {
+		log.Printf("⚠️  Webhook: failed to record delivery %s: %v", deliveryID, err)
+	} else if duplicate {
+		log.Printf("ℹ️ Webhook: ignoring duplicate delivery %s", deliveryID)
+		c.JSON(http.StatusOK, WebhookResponse{
+			Status:  "duplicate",
+			Message: "Delivery already processed",
+		})
+		return
+	}
+
+	// Step 4: Determine event type and resolve secret
 	eventType := c.GetHeader("X-GitHub-Event")
 	contentType := c.GetHeader("Content-Type")
 
@@ -1971,10 +2533,12 @@ func handleGitHubWebhook(c *gin.Context) {
 
 	secretOverride := resolveWebhookSecret(eventType, payloadBody)
 
-	// Step 3: Verify the signature (security check)
+	// Step 5: Verify the signature (security check)
 	signature := c.GetHeader("X-Hub-Signature-256")
 	if !verifyGitHubSignature(body, signature, secretOverride) {
 		log.Printf("❌ Webhook: Invalid signature from IP: %s", c.ClientIP())
+		updateDeliveryStatus(deliveryID, "failed_verification")
+		enqueueWebhookRetry(WebhookRetryItem{DeliveryID: deliveryID, Provider: "github", Headers: c.Request.Header.Clone(), Body: body, Attempt: 1})
 		c.JSON(http.StatusUnauthorized, WebhookResponse{
 			Status:  "error",
 			Message: "Invalid signature",
@@ -1982,17 +2546,18 @@ func handleGitHubWebhook(c *gin.Context) {
 		return
 	}
 
-	// Step 4: Check the event type
-	deliveryID := c.GetHeader("X-GitHub-Delivery")
-
 	log.Printf("📥 Webhook received: event=%s, delivery=%s", eventType, deliveryID)
 
-	// Step 5: Route to appropriate handler based on event type
+	// Step 6: Route to appropriate handler based on event type
 	switch eventType {
 	case "push":
-		handlePushEvent(c, payloadBody)
+		result, jobID := handlePushEvent(c, payloadBody)
+		updateDeliveryStatus(deliveryID, "delivered")
+		recordDeliveryResult(deliveryID, jobID, result)
 	case "pull_request":
-		handlePullRequestEvent(c, payloadBody)
+		result, jobID := handlePullRequestEvent(c, payloadBody)
+		updateDeliveryStatus(deliveryID, "delivered")
+		recordDeliveryResult(deliveryID, jobID, result)
 	case "ping":
 		// GitHub sends a ping event when webhook is first configured
 		c.JSON(http.StatusOK, WebhookResponse{
@@ -2009,14 +2574,16 @@ func handleGitHubWebhook(c *gin.Context) {
 	}
 }
 
-// verifyGitHubSignature validates the X-Hub-Signature-256 header
-// This ensures the request actually came from GitHub
+// verifyGitHubSignature validates the X-Hub-Signature-256 header against
+// every secret candidateWebhookSecrets resolves for this delivery. Checking
+// the whole list (rather than just the newest secret) is what lets operators
+// roll GITHUB_WEBHOOK_SECRET without downtime: add the new secret alongside
+// the old one, wait for GitHub's webhook config to be updated to it, then
+// drop the old one — deliveries signed with either secret verify in the
+// meantime.
 func verifyGitHubSignature(payload []byte, signature string, secretOverride string) bool {
-	secret := secretOverride
-	if secret == "" {
-		secret = getEnv("GITHUB_WEBHOOK_SECRET", "")
-	}
-	if secret == "" {
+	secrets := candidateWebhookSecrets(secretOverride)
+	if len(secrets) == 0 {
 		log.Println("⚠️ Warning: webhook secret not set, skipping signature verification")
 		return true // Allow in development, but log warning
 	}
@@ -2029,16 +2596,39 @@ func verifyGitHubSignature(payload []byte, signature string, secretOverride stri
 	if !strings.HasPrefix(signature, "sha256=") {
 		return false
 	}
-
 	expectedMAC := signature[7:] // Remove "sha256=" prefix
 
-	// Compute HMAC-SHA256
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	actualMAC := hex.EncodeToString(mac.Sum(nil))
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		actualMAC := hex.EncodeToString(mac.Sum(nil))
+
+		// Constant-time comparison to prevent timing attacks
+		if hmac.Equal([]byte(expectedMAC), []byte(actualMAC)) {
+			return true
+		}
+	}
+	return false
+}
 
-	// Constant-time comparison to prevent timing attacks
-	return hmac.Equal([]byte(expectedMAC), []byte(actualMAC))
+// candidateWebhookSecrets returns every secret a valid delivery may be
+// signed with: the repo-specific secretOverride (if any), the single
+// GITHUB_WEBHOOK_SECRET env var, and any additional secrets listed in
+// GITHUB_WEBHOOK_SECRETS (comma-separated) for in-progress rotations.
+func candidateWebhookSecrets(secretOverride string) []string {
+	var secrets []string
+	if secretOverride != "" {
+		secrets = append(secrets, secretOverride)
+	}
+	if single := getEnv("GITHUB_WEBHOOK_SECRET", ""); single != "" {
+		secrets = append(secrets, single)
+	}
+	for _, s := range strings.Split(getEnv("GITHUB_WEBHOOK_SECRETS", ""), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
 }
 
 // resolveWebhookSecret attempts to resolve a repo-specific webhook secret
@@ -2127,8 +2717,29 @@ func signGitHubPayload(payload []byte, secret string) string {
 	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
+// scpLikeSSHRepoURL matches the SCP-like SSH form git forges use for clone
+// URLs, e.g. "git@gitlab.com:group/project.git".
+var scpLikeSSHRepoURL = regexp.MustCompile(`^[\w.-]+@([\w.-]+):(.+)$`)
+
+// normalizeRepoURL canonicalizes a repo URL so the same repository resolves
+// to the same string regardless of which clone form a webhook payload or API
+// caller used: SCP-like SSH ("git@gitlab.com:g/p.git") becomes an https URL,
+// embedded userinfo ("https://user@bitbucket.org/g/p") is stripped, and the
+// result is lowercased with any ".git" suffix and trailing slash trimmed.
 func normalizeRepoURL(repoURL string) string {
-	normalized := strings.ToLower(strings.TrimSpace(repoURL))
+	normalized := strings.TrimSpace(repoURL)
+
+	if m := scpLikeSSHRepoURL.FindStringSubmatch(normalized); m != nil {
+		normalized = "https://" + m[1] + "/" + m[2]
+	}
+
+	if parsed, err := url.Parse(normalized); err == nil && parsed.User != nil {
+		parsed.User = nil
+		normalized = parsed.String()
+	}
+
+	normalized = strings.ToLower(normalized)
+	normalized = strings.TrimSuffix(normalized, "/")
 	normalized = strings.TrimSuffix(normalized, ".git")
 	normalized = strings.TrimSuffix(normalized, "/")
 	return normalized
@@ -2193,12 +2804,22 @@ func fetchGraphEngineJSON(baseURL, endpoint string) (map[string]interface{}, err
 }
 
 func fetchGraphEngineGraph(baseURL, repoID string, maxNodes int) (*GraphEngineGraphResponse, []string, error) {
+	return fetchGraphEngineGraphForJob(baseURL, repoID, "", maxNodes)
+}
+
+// fetchGraphEngineGraphForJob is fetchGraphEngineGraph with an optional
+// job_id pin, used to resolve a promoted environment's graph instead of the
+// latest analysis. The graph engine is trusted to honor job_id when present.
+func fetchGraphEngineGraphForJob(baseURL, repoID, jobID string, maxNodes int) (*GraphEngineGraphResponse, []string, error) {
 	warnings := []string{}
 	limit := maxNodes
 	if limit <= 0 {
 		limit = 5000
 	}
 	url := fmt.Sprintf("%s/api/graph/%s?limit=%d&offset=0", strings.TrimRight(baseURL, "/"), repoID, limit)
+	if jobID != "" {
+		url += "&job_id=" + jobID
+	}
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, warnings, err
@@ -2316,8 +2937,10 @@ func buildMarkdownExport(graph *GraphEngineGraphResponse) string {
 	}, "\n")
 }
 
-// handlePushEvent processes GitHub push events
-func handlePushEvent(c *gin.Context, body []byte) {
+// handlePushEvent processes GitHub push events, returning the delivery
+// outcome and job ID (empty for a debounced push, which doesn't have one
+// yet) so the caller can record them against the webhook_deliveries row.
+func handlePushEvent(c *gin.Context, body []byte) (result, jobID string) {
 	var payload GitHubPushPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		log.Printf("❌ Webhook: Failed to parse push payload: %v", err)
@@ -2325,7 +2948,7 @@ func handlePushEvent(c *gin.Context, body []byte) {
 			Status:  "error",
 			Message: "Invalid push payload",
 		})
-		return
+		return "error", ""
 	}
 
 	// Extract branch name from ref (refs/heads/main -> main)
@@ -2344,33 +2967,29 @@ func handlePushEvent(c *gin.Context, body []byte) {
 			Status:  "skipped",
 			Message: "No analyzable code files were changed",
 		})
-		return
+		return "skipped", ""
 	}
 
 	// Create and queue analysis job
-	jobID, err := createWebhookAnalysisJob(payload.Repository.CloneURL, branch, "push", changedFiles, removedFiles)
-	if err != nil {
-		log.Printf("❌ Webhook: Failed to create analysis job: %v", err)
-		c.JSON(http.StatusInternalServerError, WebhookResponse{
-			Status:  "error",
-			Message: "Failed to create analysis job",
-		})
-		return
-	}
-
-	log.Printf("✅ Webhook: Created analysis job %s for push to %s/%s",
-		jobID, payload.Repository.FullName, branch)
+	debouncer.Push(payload.Repository.CloneURL, branch, pushEvent{
+		before:       payload.Before,
+		after:        payload.After,
+		changedFiles: changedFiles,
+		removedFiles: removedFiles,
+	})
 
-	// Return 200 OK immediately (must be < 500ms for GitHub)
+	log.Printf("⏳ Webhook: push to %s/%s queued for debounced analysis", payload.Repository.FullName, branch)
 	c.JSON(http.StatusOK, WebhookResponse{
-		Status:  "queued",
-		Message: "Analysis job created",
-		JobID:   jobID,
+		Status:  "debounced",
+		Message: "Push accepted, analysis job will be created after the quiet period",
 	})
+	return "debounced", ""
 }
 
-// handlePullRequestEvent processes GitHub pull request events
-func handlePullRequestEvent(c *gin.Context, body []byte) {
+// handlePullRequestEvent processes GitHub pull request events, returning the
+// delivery outcome and job ID (empty when no job was created) so the caller
+// can record them against the webhook_deliveries row.
+func handlePullRequestEvent(c *gin.Context, body []byte) (result, jobID string) {
 	var payload GitHubPullRequestPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		log.Printf("❌ Webhook: Failed to parse pull_request payload: %v", err)
@@ -2378,7 +2997,7 @@ func handlePullRequestEvent(c *gin.Context, body []byte) {
 			Status:  "error",
 			Message: "Invalid pull_request payload",
 		})
-		return
+		return "error", ""
 	}
 
 	// Only process specific actions
@@ -2394,7 +3013,7 @@ func handlePullRequestEvent(c *gin.Context, body []byte) {
 			Status:  "ignored",
 			Message: fmt.Sprintf("Pull request action '%s' is not processed", payload.Action),
 		})
-		return
+		return "ignored", ""
 	}
 
 	branch := payload.PullRequest.Head.Ref
@@ -2402,11 +3021,16 @@ func handlePullRequestEvent(c *gin.Context, body []byte) {
 	log.Printf("🔀 Pull request event: repo=%s, PR=#%d, action=%s, branch=%s",
 		payload.Repository.FullName, payload.Number, payload.Action, branch)
 
-	// Create and queue analysis job for the PR branch
-	jobID, err := createWebhookAnalysisJob(
+	// Create and queue analysis job for the PR branch, merging into the
+	// ref's pending slot if one is already running and dropping the delivery
+	// outright if it's a near-duplicate of a just-completed job (e.g. GitHub
+	// retrying the same pull_request delivery).
+	jobID, merged, dropped, err := submitWebhookAnalysisJob(
 		payload.Repository.CloneURL,
 		branch,
 		"pull_request",
+		"", // PR events don't carry a single before/after commit range
+		payload.PullRequest.Head.SHA,
 		nil, // PR events don't include file changes, analyze everything
 		nil,
 	)
@@ -2416,17 +3040,38 @@ func handlePullRequestEvent(c *gin.Context, body []byte) {
 			Status:  "error",
 			Message: "Failed to create analysis job",
 		})
-		return
+		return "error", ""
+	}
+
+	if dropped {
+		log.Printf("ℹ️ Webhook: dropped near-duplicate pull_request delivery for PR #%d on %s", payload.Number, payload.Repository.FullName)
+		c.JSON(http.StatusOK, WebhookResponse{
+			Status:  "duplicate",
+			Message: fmt.Sprintf("Pull request #%d delivery ignored as a near-duplicate", payload.Number),
+		})
+		return "duplicate", ""
+	}
+
+	if merged {
+		log.Printf("⏳ Webhook: PR #%d on %s merged into its ref's pending slot", payload.Number, payload.Repository.FullName)
+		c.JSON(http.StatusOK, WebhookResponse{
+			Status:  "debounced",
+			Message: fmt.Sprintf("Pull request #%d merged behind its ref's running analysis", payload.Number),
+		})
+		return "debounced", ""
 	}
 
 	log.Printf("✅ Webhook: Created analysis job %s for PR #%d on %s",
 		jobID, payload.Number, payload.Repository.FullName)
 
+	go publishPendingCommitStatus(payload.Repository.CloneURL, payload.PullRequest.Head.SHA, jobID)
+
 	c.JSON(http.StatusOK, WebhookResponse{
 		Status:  "queued",
 		Message: fmt.Sprintf("Analysis job created for PR #%d", payload.Number),
 		JobID:   jobID,
 	})
+	return "queued", jobID
 }
 
 // extractBranchName extracts the branch name from a git ref
@@ -2486,8 +3131,10 @@ func hasAnalyzableFiles(files []string) bool {
 	return false
 }
 
-// createWebhookAnalysisJob creates a new analysis job from a webhook event
-func createWebhookAnalysisJob(repoURL, branch, trigger string, changedFiles []string, removedFiles []string) (string, error) {
+// createWebhookAnalysisJob creates a new analysis job from a webhook event.
+// before/after are the commit range the job covers; pass "" for either when
+// the trigger doesn't have a meaningful range (e.g. pull_request events).
+func createWebhookAnalysisJob(repoURL, branch, trigger, before, after string, changedFiles []string, removedFiles []string) (string, error) {
 	jobID := uuid.New().String()
 	repoID := generateRepoID(repoURL)
 
@@ -2497,6 +3144,13 @@ func createWebhookAnalysisJob(repoURL, branch, trigger string, changedFiles []st
 		"source":  "webhook",
 	}
 
+	if before != "" {
+		options["before_sha"] = before
+	}
+	if after != "" {
+		options["after_sha"] = after
+	}
+
 	if len(changedFiles) > 0 {
 		// Store changed files (truncate if too many)
 		maxFiles := 100
@@ -2548,6 +3202,15 @@ func createWebhookAnalysisJob(repoURL, branch, trigger string, changedFiles []st
 		return "", fmt.Errorf("failed to queue job: %w", err)
 	}
 
+	go notifyAll(NotificationEvent{
+		Kind:      "job_queued",
+		RepoID:    repoID,
+		RepoURL:   repoURL,
+		JobID:     jobID,
+		Status:    "QUEUED",
+		CommitSHA: after,
+	})
+
 	return jobID, nil
 }
 
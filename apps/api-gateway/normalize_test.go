@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRepoURL_HandlesSCPLikeSSH(t *testing.T) {
+	assert.Equal(t, "https://gitlab.com/g/p", normalizeRepoURL("git@gitlab.com:g/p.git"))
+}
+
+func TestNormalizeRepoURL_StripsUserinfo(t *testing.T) {
+	assert.Equal(t, "https://bitbucket.org/g/p", normalizeRepoURL("https://user@bitbucket.org/g/p.git"))
+}
+
+func TestNormalizeRepoURL_PlainHTTPSUnaffected(t *testing.T) {
+	assert.Equal(t, "https://github.com/g/p", normalizeRepoURL("https://github.com/g/p.git/"))
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// notifierRetryQueueKey is a Redis sorted set of outbound notifier
+// deliveries waiting out their retry backoff, scored by the unix
+// millisecond timestamp they become due. Mirrors webhookRetryQueueKey's
+// shape for inbound deliveries.
+const notifierRetryQueueKey = "notifier_retry_queue"
+
+// notifierRetryDeadKey holds outbound deliveries that exhausted
+// notifierRetryBackoff.
+const notifierRetryDeadKey = "notifier_retry_queue:dead"
+
+// notifierRetryBackoff is the delay before each retry of a failed outbound
+// notification: 1s, 5s, 30s, 5m. Once exhausted the delivery is
+// dead-lettered.
+var notifierRetryBackoff = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	5 * time.Minute,
+}
+
+// NotifierRetryItem is the envelope persisted to notifierRetryQueueKey so a
+// failed delivery can be retried without re-deriving the triggering event.
+type NotifierRetryItem struct {
+	TargetID int               `json:"target_id"`
+	Event    NotificationEvent `json:"event"`
+	Attempt  int               `json:"attempt"`
+}
+
+// ensureNotificationDeliveriesSchema creates the notification_deliveries
+// ledger if migrations were not applied.
+func ensureNotificationDeliveriesSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS notification_deliveries (
+			id SERIAL PRIMARY KEY,
+			target_id INTEGER NOT NULL REFERENCES repo_notifiers(id) ON DELETE CASCADE,
+			job_id VARCHAR(255),
+			status VARCHAR(32) NOT NULL,
+			attempt INTEGER NOT NULL DEFAULT 1,
+			response_code INTEGER,
+			response_body TEXT,
+			delivered_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS idx_notification_deliveries_target_id ON notification_deliveries(target_id);
+	`)
+	return err
+}
+
+// recordNotificationDelivery inserts a ledger row for one delivery attempt.
+func recordNotificationDelivery(targetID int, jobID, status string, attempt, responseCode int, responseBody string) {
+	_, err := db.Exec(`
+		INSERT INTO notification_deliveries (target_id, job_id, status, attempt, response_code, response_body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, targetID, jobID, status, attempt, responseCode, responseBody)
+	if err != nil {
+		log.Printf("⚠️  Failed to record notification delivery for target %d: %v", targetID, err)
+	}
+}
+
+// enqueueNotifierRetry schedules item for another attempt after the backoff
+// delay for its attempt number, or dead-letters it once notifierRetryBackoff
+// is exhausted.
+func enqueueNotifierRetry(targetID int, event NotificationEvent, attempt int) {
+	item := NotifierRetryItem{TargetID: targetID, Event: event, Attempt: attempt}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal notifier retry item for target %d: %v", targetID, err)
+		return
+	}
+
+	delay := notifierRetryBackoff[attempt-1]
+	dueAt := float64(time.Now().Add(delay).UnixMilli())
+	if err := redisClient.ZAdd(ctx, notifierRetryQueueKey, &redis.Z{Score: dueAt, Member: string(body)}).Err(); err != nil {
+		log.Printf("⚠️  Failed to schedule notifier retry for target %d: %v", targetID, err)
+		return
+	}
+	log.Printf("🔁 Notification to target %d scheduled for retry %d/%d in %s", targetID, attempt, len(notifierRetryBackoff), delay)
+}
+
+func deadLetterNotifierDelivery(targetID int, event NotificationEvent, attempt int) {
+	item := NotifierRetryItem{TargetID: targetID, Event: event, Attempt: attempt}
+	body, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+	if err := redisClient.LPush(ctx, notifierRetryDeadKey, body).Err(); err != nil {
+		log.Printf("⚠️  Failed to dead-letter notification to target %d: %v", targetID, err)
+	}
+	log.Printf("💀 Notification to target %d dead-lettered after %d attempts", targetID, attempt)
+}
+
+// NotifierRetryDispatcher drains notifierRetryQueueKey on an interval,
+// retrying deliveries whose backoff has elapsed.
+type NotifierRetryDispatcher struct {
+	pollInterval time.Duration
+}
+
+// NewNotifierRetryDispatcher builds a dispatcher that polls every
+// pollInterval.
+func NewNotifierRetryDispatcher(pollInterval time.Duration) *NotifierRetryDispatcher {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &NotifierRetryDispatcher{pollInterval: pollInterval}
+}
+
+// Run blocks, retrying due notifier deliveries until stop is closed.
+func (d *NotifierRetryDispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.processDue()
+		}
+	}
+}
+
+func (d *NotifierRetryDispatcher) processDue() {
+	nowMs := float64(time.Now().UnixMilli())
+	due, err := redisClient.ZRangeByScore(ctx, notifierRetryQueueKey, &redis.ZRangeBy{
+		Min: "0",
+		Max: fmt.Sprintf("%f", nowMs),
+	}).Result()
+	if err != nil || len(due) == 0 {
+		return
+	}
+
+	for _, raw := range due {
+		redisClient.ZRem(ctx, notifierRetryQueueKey, raw)
+
+		var item NotifierRetryItem
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			log.Printf("⚠️  Failed to decode notifier retry item: %v", err)
+			continue
+		}
+
+		targets, err := loadActiveRepoNotifierTargets(item.Event.RepoID)
+		if err != nil {
+			log.Printf("⚠️  Failed to reload notifier target %d for retry: %v", item.TargetID, err)
+			continue
+		}
+		var target *notifierTarget
+		for i := range targets {
+			if targets[i].id == item.TargetID {
+				target = &targets[i]
+				break
+			}
+		}
+		if target == nil {
+			log.Printf("ℹ️  Notifier target %d no longer active, dropping retry", item.TargetID)
+			continue
+		}
+
+		deliverToNotifierTarget(*target, item.Event, item.Attempt+1)
+	}
+}
+
+// listNotifierDeliveries handles GET /api/v1/notifiers/:id/deliveries
+func listNotifierDeliveries(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, "id", "Invalid notifier ID")
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT id, target_id, job_id, status, attempt, response_code, response_body, delivered_at
+		FROM notification_deliveries
+		WHERE target_id = $1
+		ORDER BY delivered_at DESC
+		LIMIT 100
+	`, targetID)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
+		return
+	}
+	defer rows.Close()
+
+	type notificationDelivery struct {
+		ID           int       `json:"id"`
+		TargetID     int       `json:"target_id"`
+		JobID        string    `json:"job_id"`
+		Status       string    `json:"status"`
+		Attempt      int       `json:"attempt"`
+		ResponseCode int       `json:"response_code"`
+		ResponseBody string    `json:"response_body"`
+		DeliveredAt  time.Time `json:"delivered_at"`
+	}
+
+	deliveries := []notificationDelivery{}
+	for rows.Next() {
+		var d notificationDelivery
+		var jobID, responseBody sql.NullString
+		var responseCode sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.TargetID, &jobID, &d.Status, &d.Attempt, &responseCode, &responseBody, &d.DeliveredAt); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		d.JobID = jobID.String
+		d.ResponseBody = responseBody.String
+		d.ResponseCode = int(responseCode.Int64)
+		deliveries = append(deliveries, d)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// replayNotifierDelivery handles POST
+// /api/v1/notifiers/:id/deliveries/:delivery_id/replay by sending a new
+// "replay" event, carrying that delivery's job ID, through the target's
+// current configuration. Only the job ID is stored per delivery, so the
+// original event's kind, status and summary are not re-sent.
+func replayNotifierDelivery(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, "id", "Invalid notifier ID")
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		validationError(c, "delivery_id", "Invalid delivery ID")
+		return
+	}
+
+	var jobID string
+	err = db.QueryRow(`SELECT job_id FROM notification_deliveries WHERE id = $1 AND target_id = $2`, deliveryID, targetID).Scan(&jobID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	} else if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load delivery"})
+		return
+	}
+
+	var notifierType string
+	var config json.RawMessage
+	var repoURL string
+	err = db.QueryRow(`
+		SELECT n.type, n.config, r.url
+		FROM repo_notifiers n
+		JOIN repositories r ON n.repo_id = r.id
+		WHERE n.id = $1
+	`, targetID).Scan(&notifierType, &config, &repoURL)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifier not found"})
+		return
+	} else if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notifier"})
+		return
+	}
+
+	notifier, err := buildNotifierFromConfig(notifierType, config)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Invalid notifier config: %v", err)})
+		return
+	}
+
+	event := NotificationEvent{
+		Kind:    "replay",
+		RepoID:  generateRepoID(repoURL),
+		RepoURL: repoURL,
+		JobID:   jobID,
+		Status:  "REPLAYED",
+	}
+
+	notifyCtx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := notifier.Notify(notifyCtx, event); err != nil {
+		recordNotificationDelivery(targetID, jobID, "failed", 1, 0, err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Replay failed: %v", err)})
+		return
+	}
+
+	recordNotificationDelivery(targetID, jobID, "delivered", 1, 0, "")
+	c.JSON(http.StatusOK, gin.H{"status": "delivered"})
+}
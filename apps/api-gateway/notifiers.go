@@ -0,0 +1,811 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationEvent describes one analysis lifecycle event to fan out to
+// configured notifiers.
+type NotificationEvent struct {
+	Kind      string `json:"kind"` // "job_queued", "job_completed", "job_failed", "job_cancelled", "analysis_findings_new"
+	RepoID    string `json:"repo_id"`
+	RepoURL   string `json:"repo_url"`
+	JobID     string `json:"job_id"`
+	Status    string `json:"status"`
+	Summary   string `json:"summary,omitempty"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+	Author    string `json:"author,omitempty"`
+	// MermaidURL links to the export endpoint for the graph this event is
+	// about, so chat notifications can deep-link straight to the diagram.
+	MermaidURL string `json:"mermaid_url,omitempty"`
+	// TopFindings is a short excerpt of the analysis's most notable findings,
+	// when the triggering ResultSummary carried any.
+	TopFindings []string `json:"top_findings,omitempty"`
+}
+
+// eventKindMatchesFilter reports whether kind is matched by filters, the
+// dotted event-filter DSL a notification target subscribes with (e.g.
+// "job.completed", "analysis.findings.new"). An empty filter list matches
+// every event, preserving the original "subscribe to everything" behavior.
+func eventKindMatchesFilter(kind string, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if strings.ReplaceAll(filter, ".", "_") == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier delivers a NotificationEvent to one outbound channel.
+type Notifier interface {
+	Notify(ctx context.Context, event NotificationEvent) error
+	Name() string
+}
+
+// globalNotifiers are the process-wide notifiers configured via the
+// NOTIFIERS env var; they receive every event regardless of repo.
+var globalNotifiers []Notifier
+
+// initNotifiers builds globalNotifiers from the comma-separated NOTIFIERS
+// env var (e.g. "slack,http"), reading each backend's own config from its
+// conventional env vars.
+func initNotifiers() {
+	kinds := splitAndTrim(getEnv("NOTIFIERS", ""))
+	globalNotifiers = make([]Notifier, 0, len(kinds))
+
+	for _, kind := range kinds {
+		notifier, err := buildNotifierFromEnv(strings.ToLower(kind))
+		if err != nil {
+			log.Printf("⚠️  Notifiers: skipping %q: %v", kind, err)
+			continue
+		}
+		globalNotifiers = append(globalNotifiers, notifier)
+		log.Printf("🔔 Notifiers: registered global %s notifier", notifier.Name())
+	}
+}
+
+func buildNotifierFromEnv(kind string) (Notifier, error) {
+	switch kind {
+	case "slack":
+		url := getEnv("SLACK_WEBHOOK_URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("SLACK_WEBHOOK_URL not set")
+		}
+		return SlackNotifier{WebhookURL: url}, nil
+	case "discord":
+		url := getEnv("DISCORD_WEBHOOK_URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("DISCORD_WEBHOOK_URL not set")
+		}
+		return DiscordNotifier{WebhookURL: url}, nil
+	case "http":
+		targetURL := getEnv("NOTIFIER_HTTP_URL", "")
+		if targetURL == "" {
+			return nil, fmt.Errorf("NOTIFIER_HTTP_URL not set")
+		}
+		return HTTPNotifier{URL: targetURL, Secret: getEnv("NOTIFIER_HTTP_SECRET", "")}, nil
+	case "email", "smtp":
+		notifier := SMTPNotifier{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+			To:       getEnv("SMTP_TO", ""),
+		}
+		if notifier.Host == "" || notifier.From == "" || notifier.To == "" {
+			return nil, fmt.Errorf("SMTP_HOST, SMTP_FROM, and SMTP_TO must all be set")
+		}
+		return notifier, nil
+	case "teams", "msteams":
+		webhookURL := getEnv("TEAMS_WEBHOOK_URL", "")
+		if webhookURL == "" {
+			return nil, fmt.Errorf("TEAMS_WEBHOOK_URL not set")
+		}
+		return TeamsNotifier{WebhookURL: webhookURL}, nil
+	case "matrix":
+		notifier := MatrixNotifier{
+			HomeserverURL: getEnv("MATRIX_HOMESERVER_URL", ""),
+			RoomID:        getEnv("MATRIX_ROOM_ID", ""),
+			AccessToken:   getEnv("MATRIX_ACCESS_TOKEN", ""),
+		}
+		if notifier.HomeserverURL == "" || notifier.RoomID == "" || notifier.AccessToken == "" {
+			return nil, fmt.Errorf("MATRIX_HOMESERVER_URL, MATRIX_ROOM_ID, and MATRIX_ACCESS_TOKEN must all be set")
+		}
+		return notifier, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier kind")
+	}
+}
+
+// notifyAll fans event out to every global notifier plus any active
+// repo-specific subscriptions for event.RepoID whose event filter matches,
+// logging failures without blocking the caller (notification delivery must
+// never fail a job transition). Global notifiers have no target row to track
+// retries against, so they get a single best-effort attempt; repo-specific
+// targets get their attempt persisted to notification_deliveries and retried
+// with backoff on failure via notifierRetryDispatcher.
+func notifyAll(event NotificationEvent) {
+	for _, notifier := range globalNotifiers {
+		go func(n Notifier) {
+			notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := n.Notify(notifyCtx, event); err != nil {
+				log.Printf("⚠️  Notifier %s failed for job %s: %v", n.Name(), event.JobID, err)
+			}
+		}(notifier)
+	}
+
+	targets, err := loadActiveRepoNotifierTargets(event.RepoID)
+	if err != nil {
+		log.Printf("⚠️  Notifiers: failed to load repo subscriptions for %s: %v", event.RepoID, err)
+		return
+	}
+
+	for _, target := range targets {
+		if !eventKindMatchesFilter(event.Kind, target.events) {
+			continue
+		}
+		go deliverToNotifierTarget(target, event, 1)
+	}
+}
+
+// SlackNotifier posts to a Slack incoming webhook URL.
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+func (s SlackNotifier) Name() string { return "slack" }
+
+func (s SlackNotifier) Notify(ctx context.Context, event NotificationEvent) error {
+	body, err := json.Marshal(map[string]string{"text": formatNotificationText(event)})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.WebhookURL, body, nil)
+}
+
+// DiscordNotifier posts to a Discord incoming webhook URL.
+type DiscordNotifier struct {
+	WebhookURL string
+}
+
+func (d DiscordNotifier) Name() string { return "discord" }
+
+func (d DiscordNotifier) Notify(ctx context.Context, event NotificationEvent) error {
+	body, err := json.Marshal(map[string]string{"content": formatNotificationText(event)})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, d.WebhookURL, body, nil)
+}
+
+// HTTPNotifier POSTs the raw NotificationEvent JSON to an arbitrary URL,
+// signed the same way GitHub webhook deliveries are: HMAC-SHA256 over the
+// body, hex-encoded and prefixed "sha256=".
+type HTTPNotifier struct {
+	URL    string
+	Secret string
+}
+
+func (h HTTPNotifier) Name() string { return "http" }
+
+func (h HTTPNotifier) Notify(ctx context.Context, event NotificationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	if h.Secret != "" {
+		headers["X-ArchMind-Signature-256"] = signNotificationPayload(body, h.Secret)
+	}
+	return postJSON(ctx, h.URL, body, headers)
+}
+
+// signNotificationPayload signs body the same way signGitHubPayload signs
+// inbound webhook payloads: HMAC-SHA256, hex-encoded, "sha256=" prefixed.
+func signNotificationPayload(body []byte, secret string) string {
+	return signGitHubPayload(body, secret)
+}
+
+// TeamsNotifier posts an Office 365 Connector MessageCard to a Microsoft
+// Teams incoming webhook URL.
+type TeamsNotifier struct {
+	WebhookURL string
+}
+
+func (t TeamsNotifier) Name() string { return "teams" }
+
+func (t TeamsNotifier) Notify(ctx context.Context, event NotificationEvent) error {
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"summary":    formatNotificationText(event),
+		"themeColor": teamsThemeColor(event),
+		"title":      fmt.Sprintf("ArchMind: job %s", event.Status),
+		"text":       formatNotificationText(event),
+	}
+	if len(event.TopFindings) > 0 || event.MermaidURL != "" {
+		card["sections"] = []map[string]interface{}{notificationCardSection(event)}
+	}
+
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, t.WebhookURL, body, nil)
+}
+
+func teamsThemeColor(event NotificationEvent) string {
+	switch event.Status {
+	case "COMPLETED":
+		return "28A745"
+	case "FAILED":
+		return "D73A49"
+	default:
+		return "6F42C1"
+	}
+}
+
+func notificationCardSection(event NotificationEvent) map[string]interface{} {
+	section := map[string]interface{}{}
+	if len(event.TopFindings) > 0 {
+		section["activityTitle"] = "Top findings"
+		section["activityText"] = strings.Join(event.TopFindings, "; ")
+	}
+	if event.MermaidURL != "" {
+		section["facts"] = []map[string]string{{"name": "Diagram", "value": event.MermaidURL}}
+	}
+	return section
+}
+
+// MatrixNotifier posts an m.room.message event to a Matrix room via the
+// homeserver's client-server API.
+type MatrixNotifier struct {
+	HomeserverURL string
+	RoomID        string
+	AccessToken   string
+}
+
+func (m MatrixNotifier) Name() string { return "matrix" }
+
+func (m MatrixNotifier) Notify(ctx context.Context, event NotificationEvent) error {
+	text := formatNotificationText(event)
+	html := text
+	if event.MermaidURL != "" {
+		html += fmt.Sprintf(` (<a href="%s">diagram</a>)`, event.MermaidURL)
+	}
+	if len(event.TopFindings) > 0 {
+		html += "<br/>Top findings: " + strings.Join(event.TopFindings, "; ")
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"msgtype":        "m.text",
+		"body":           text,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": html,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message", strings.TrimRight(m.HomeserverURL, "/"), url.PathEscape(m.RoomID))
+	headers := map[string]string{"Authorization": "Bearer " + m.AccessToken}
+	return postJSON(ctx, endpoint, body, headers)
+}
+
+// SMTPNotifier emails a plaintext summary of the event via SMTP.
+type SMTPNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       string
+}
+
+func (s SMTPNotifier) Name() string { return "email" }
+
+func (s SMTPNotifier) Notify(_ context.Context, event NotificationEvent) error {
+	subject := fmt.Sprintf("[ArchMind] Job %s: %s", event.JobID, event.Status)
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, formatNotificationText(event))
+
+	addr := fmt.Sprintf("%s:%s", s.Host, s.Port)
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(addr, auth, s.From, []string{s.To}, []byte(msg))
+}
+
+// mermaidExportURL returns the export endpoint for repoID's graph, mirroring
+// jobLogTailURL's relative-path convention for JobUpdate.LogURL.
+func mermaidExportURL(repoID string) string {
+	if repoID == "" {
+		return ""
+	}
+	return fmt.Sprintf("/api/export/%s", repoID)
+}
+
+// extractTopFindings pulls a short excerpt of notable findings out of a
+// job's ResultSummary, if the worker reported any under "findings".
+func extractTopFindings(summary map[string]interface{}) []string {
+	raw, ok := summary["findings"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	const maxFindings = 5
+	findings := make([]string, 0, maxFindings)
+	for _, entry := range raw {
+		if len(findings) >= maxFindings {
+			break
+		}
+		switch v := entry.(type) {
+		case string:
+			findings = append(findings, v)
+		case map[string]interface{}:
+			if title, ok := v["title"].(string); ok {
+				findings = append(findings, title)
+			}
+		}
+	}
+	return findings
+}
+
+func formatNotificationText(event NotificationEvent) string {
+	text := fmt.Sprintf("Job %s for %s is now %s", event.JobID, event.RepoURL, event.Status)
+	if event.Summary != "" {
+		text += ": " + event.Summary
+	}
+	if len(event.TopFindings) > 0 {
+		text += "\nTop findings: " + strings.Join(event.TopFindings, "; ")
+	}
+	if event.MermaidURL != "" {
+		text += "\nDiagram: " + event.MermaidURL
+	}
+	return text
+}
+
+func postJSON(ctx context.Context, targetURL string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// =============================================================================
+// repo_notifiers persistence and management API
+// =============================================================================
+
+// RepoNotifier is a stored per-repo notifier subscription.
+type RepoNotifier struct {
+	ID        int             `json:"id"`
+	RepoID    int             `json:"repo_id"`
+	RepoURL   string          `json:"repo_url,omitempty"`
+	Type      string          `json:"type"`
+	Config    json.RawMessage `json:"config"`
+	// Events is the dotted event-filter DSL this subscription listens for
+	// (e.g. "job.completed", "analysis.findings.new"); empty means all events.
+	Events    []string  `json:"events,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RepoNotifierCreateRequest is the request body for POST /api/v1/notifiers.
+type RepoNotifierCreateRequest struct {
+	RepoID  *int            `json:"repo_id,omitempty"`
+	RepoURL string          `json:"repo_url,omitempty"`
+	Type    string          `json:"type"`
+	Config  json.RawMessage `json:"config"`
+	Events  []string        `json:"events,omitempty"`
+}
+
+type repoNotifierListResponse struct {
+	Notifiers []RepoNotifier `json:"notifiers"`
+}
+
+// ensureRepoNotifiersSchema creates the repo_notifiers table if migrations
+// were not applied.
+func ensureRepoNotifiersSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS repo_notifiers (
+			id SERIAL PRIMARY KEY,
+			repo_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			type VARCHAR(32) NOT NULL,
+			config JSONB NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+	`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE repo_notifiers ADD COLUMN IF NOT EXISTS events JSONB`)
+	return err
+}
+
+// isSupportedNotifierType reports whether type is a kind this gateway can
+// build a concrete Notifier for.
+func isSupportedNotifierType(notifierType string) bool {
+	switch notifierType {
+	case "slack", "discord", "http", "email", "teams", "msteams", "matrix":
+		return true
+	default:
+		return false
+	}
+}
+
+// listRepoNotifiers handles GET /api/v1/notifiers
+func listRepoNotifiers(c *gin.Context) {
+	rows, err := db.Query(`
+		SELECT n.id, n.repo_id, r.url, n.type, n.config, n.events, n.active, n.created_at, n.updated_at
+		FROM repo_notifiers n
+		JOIN repositories r ON n.repo_id = r.id
+		ORDER BY n.created_at DESC
+	`)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifiers"})
+		return
+	}
+	defer rows.Close()
+
+	notifiers := []RepoNotifier{}
+	for rows.Next() {
+		var n RepoNotifier
+		var events json.RawMessage
+		if err := rows.Scan(&n.ID, &n.RepoID, &n.RepoURL, &n.Type, &n.Config, &events, &n.Active, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		if len(events) > 0 {
+			_ = json.Unmarshal(events, &n.Events)
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	c.JSON(http.StatusOK, repoNotifierListResponse{Notifiers: notifiers})
+}
+
+// createRepoNotifier handles POST /api/v1/notifiers
+func createRepoNotifier(c *gin.Context) {
+	var req RepoNotifierCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if !isSupportedNotifierType(strings.ToLower(req.Type)) {
+		validationError(c, "type", "Unsupported notifier type")
+		return
+	}
+
+	if req.RepoID == nil && req.RepoURL == "" {
+		validationError(c, "repo_url", "Repository URL is required")
+		return
+	}
+
+	repoID := 0
+	if req.RepoID != nil {
+		repoID = *req.RepoID
+	} else {
+		id, err := getOrCreateRepository(req.RepoURL)
+		if err != nil {
+			log.Printf("Repository error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve repository"})
+			return
+		}
+		repoID = id
+	}
+
+	config := req.Config
+	if len(config) == 0 {
+		config = json.RawMessage("{}")
+	}
+
+	events, err := json.Marshal(req.Events)
+	if err != nil {
+		events = json.RawMessage("[]")
+	}
+
+	var n RepoNotifier
+	err = db.QueryRow(`
+		INSERT INTO repo_notifiers (repo_id, type, config, events, active)
+		VALUES ($1, $2, $3, $4, true)
+		RETURNING id, created_at, updated_at
+	`, repoID, strings.ToLower(req.Type), config, events).Scan(&n.ID, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notifier"})
+		return
+	}
+
+	n.RepoID = repoID
+	n.RepoURL = req.RepoURL
+	if n.RepoURL == "" {
+		n.RepoURL = lookupRepoURL(repoID)
+	}
+	n.Type = strings.ToLower(req.Type)
+	n.Config = config
+	n.Events = req.Events
+	n.Active = true
+
+	c.JSON(http.StatusCreated, n)
+}
+
+// deleteRepoNotifier handles DELETE /api/v1/notifiers/:id
+func deleteRepoNotifier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, "id", "Invalid notifier ID")
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM repo_notifiers WHERE id = $1", id)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notifier"})
+		return
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifier not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// testRepoNotifier handles POST /api/v1/notifiers/:id/test, sending a
+// synthetic NotificationEvent through the stored notifier configuration.
+func testRepoNotifier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		validationError(c, "id", "Invalid notifier ID")
+		return
+	}
+
+	var notifierType string
+	var config json.RawMessage
+	var repoURL string
+	err = db.QueryRow(`
+		SELECT n.type, n.config, r.url
+		FROM repo_notifiers n
+		JOIN repositories r ON n.repo_id = r.id
+		WHERE n.id = $1
+	`, id).Scan(&notifierType, &config, &repoURL)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifier not found"})
+		return
+	} else if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notifier"})
+		return
+	}
+
+	notifier, err := buildNotifierFromConfig(notifierType, config)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Invalid notifier config: %v", err)})
+		return
+	}
+
+	event := NotificationEvent{
+		Kind:    "test",
+		RepoURL: repoURL,
+		JobID:   "00000000-0000-0000-0000-000000000000",
+		Status:  "TEST",
+		Summary: "This is a test notification from ArchMind",
+	}
+
+	notifyCtx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := notifier.Notify(notifyCtx, event); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Test notification failed: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "sent"})
+}
+
+// buildNotifierFromConfig constructs a Notifier from a repo_notifiers row's
+// type and JSONB config column.
+func buildNotifierFromConfig(notifierType string, config json.RawMessage) (Notifier, error) {
+	switch notifierType {
+	case "slack":
+		var cfg struct {
+			WebhookURL string `json:"webhook_url"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook_url is required")
+		}
+		return SlackNotifier{WebhookURL: cfg.WebhookURL}, nil
+
+	case "discord":
+		var cfg struct {
+			WebhookURL string `json:"webhook_url"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook_url is required")
+		}
+		return DiscordNotifier{WebhookURL: cfg.WebhookURL}, nil
+
+	case "http":
+		var cfg struct {
+			URL    string `json:"url"`
+			Secret string `json:"secret"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+			return nil, fmt.Errorf("url is required and must be valid: %w", err)
+		}
+		return HTTPNotifier{URL: cfg.URL, Secret: cfg.Secret}, nil
+
+	case "email":
+		var cfg struct {
+			Host     string `json:"host"`
+			Port     string `json:"port"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+			From     string `json:"from"`
+			To       string `json:"to"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.Host == "" || cfg.From == "" || cfg.To == "" {
+			return nil, fmt.Errorf("host, from, and to are required")
+		}
+		if cfg.Port == "" {
+			cfg.Port = "587"
+		}
+		return SMTPNotifier{Host: cfg.Host, Port: cfg.Port, Username: cfg.Username, Password: cfg.Password, From: cfg.From, To: cfg.To}, nil
+
+	case "teams", "msteams":
+		var cfg struct {
+			WebhookURL string `json:"webhook_url"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("webhook_url is required")
+		}
+		return TeamsNotifier{WebhookURL: cfg.WebhookURL}, nil
+
+	case "matrix":
+		var cfg struct {
+			HomeserverURL string `json:"homeserver_url"`
+			RoomID        string `json:"room_id"`
+			AccessToken   string `json:"access_token"`
+		}
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.HomeserverURL == "" || cfg.RoomID == "" || cfg.AccessToken == "" {
+			return nil, fmt.Errorf("homeserver_url, room_id, and access_token are required")
+		}
+		return MatrixNotifier{HomeserverURL: cfg.HomeserverURL, RoomID: cfg.RoomID, AccessToken: cfg.AccessToken}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported notifier type %q", notifierType)
+	}
+}
+
+// notifierTarget is one active repo_notifiers row resolved into a concrete
+// Notifier, paired with the target's id (for persisted delivery tracking)
+// and its dotted event-filter subscription list.
+type notifierTarget struct {
+	id       int
+	notifier Notifier
+	events   []string
+}
+
+// loadActiveRepoNotifierTargets returns the active repo_notifiers
+// subscriptions matching repoID (the deterministic UUID, as used throughout
+// AnalysisJob), each resolved into a concrete Notifier plus its event
+// filter, so notifyAll can both dispatch and persist delivery attempts
+// against the originating target row.
+func loadActiveRepoNotifierTargets(repoID string) ([]notifierTarget, error) {
+	rows, err := db.Query(`
+		SELECT n.id, n.type, n.config, n.events, r.url
+		FROM repo_notifiers n
+		JOIN repositories r ON n.repo_id = r.id
+		WHERE n.active = true
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []notifierTarget
+	for rows.Next() {
+		var id int
+		var notifierType, repoURL string
+		var config, events json.RawMessage
+		if err := rows.Scan(&id, &notifierType, &config, &events, &repoURL); err != nil {
+			continue
+		}
+		if generateRepoID(repoURL) != repoID {
+			continue
+		}
+		notifier, err := buildNotifierFromConfig(notifierType, config)
+		if err != nil {
+			log.Printf("⚠️  Notifiers: skipping misconfigured %s subscription: %v", notifierType, err)
+			continue
+		}
+		var filters []string
+		if len(events) > 0 {
+			_ = json.Unmarshal(events, &filters)
+		}
+		targets = append(targets, notifierTarget{id: id, notifier: notifier, events: filters})
+	}
+	return targets, nil
+}
+
+// deliverToNotifierTarget sends event to target.notifier, records the
+// outcome to notification_deliveries, and on failure schedules a retry
+// with backoff via notifierRetryDispatcher, giving up and dead-lettering
+// after exhausting notifierRetryBackoff.
+func deliverToNotifierTarget(target notifierTarget, event NotificationEvent, attempt int) {
+	notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := target.notifier.Notify(notifyCtx, event)
+	if err != nil {
+		log.Printf("⚠️  Notifier %s (target %d) failed for job %s, attempt %d: %v", target.notifier.Name(), target.id, event.JobID, attempt, err)
+		recordNotificationDelivery(target.id, event.JobID, "failed", attempt, 0, err.Error())
+		if attempt > len(notifierRetryBackoff) {
+			deadLetterNotifierDelivery(target.id, event, attempt)
+			return
+		}
+		enqueueNotifierRetry(target.id, event, attempt)
+		return
+	}
+
+	recordNotificationDelivery(target.id, event.JobID, "delivered", attempt, 0, "")
+}
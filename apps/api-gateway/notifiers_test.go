@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignNotificationPayload_IsDeterministicAndPrefixed(t *testing.T) {
+	body := []byte(`{"job_id":"abc"}`)
+	sig1 := signNotificationPayload(body, "s3cret")
+	sig2 := signNotificationPayload(body, "s3cret")
+
+	assert.Equal(t, sig1, sig2)
+	assert.Contains(t, sig1, "sha256=")
+}
+
+func TestSignNotificationPayload_DiffersPerSecret(t *testing.T) {
+	body := []byte(`{"job_id":"abc"}`)
+	assert.NotEqual(t, signNotificationPayload(body, "one"), signNotificationPayload(body, "two"))
+}
+
+func TestFormatNotificationText_IncludesSummaryWhenPresent(t *testing.T) {
+	withSummary := formatNotificationText(NotificationEvent{
+		JobID: "job-1", RepoURL: "https://github.com/foo/bar", Status: "COMPLETED", Summary: "3 issues found",
+	})
+	assert.Contains(t, withSummary, "job-1")
+	assert.Contains(t, withSummary, "3 issues found")
+
+	withoutSummary := formatNotificationText(NotificationEvent{
+		JobID: "job-2", RepoURL: "https://github.com/foo/bar", Status: "FAILED",
+	})
+	assert.NotContains(t, withoutSummary, ": ")
+}
+
+func TestIsSupportedNotifierType(t *testing.T) {
+	assert.True(t, isSupportedNotifierType("slack"))
+	assert.True(t, isSupportedNotifierType("discord"))
+	assert.True(t, isSupportedNotifierType("http"))
+	assert.True(t, isSupportedNotifierType("email"))
+	assert.False(t, isSupportedNotifierType("carrier-pigeon"))
+}
+
+func TestBuildNotifierFromConfig_RejectsMissingFields(t *testing.T) {
+	_, err := buildNotifierFromConfig("slack", []byte(`{}`))
+	assert.Error(t, err)
+
+	_, err = buildNotifierFromConfig("http", []byte(`{"url":"not-a-url"}`))
+	assert.Error(t, err)
+
+	_, err = buildNotifierFromConfig("email", []byte(`{"host":"smtp.example.com"}`))
+	assert.Error(t, err)
+}
+
+func TestBuildNotifierFromConfig_SucceedsWithValidConfig(t *testing.T) {
+	n, err := buildNotifierFromConfig("http", []byte(`{"url":"https://example.com/hook","secret":"s"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "http", n.Name())
+}
+
+func TestEventKindMatchesFilter(t *testing.T) {
+	assert.True(t, eventKindMatchesFilter("job_completed", nil))
+	assert.True(t, eventKindMatchesFilter("analysis_findings_new", []string{"job.completed", "analysis.findings.new"}))
+	assert.False(t, eventKindMatchesFilter("job_failed", []string{"job.completed"}))
+}
+
+func TestExtractTopFindings_CapsAtFiveAndHandlesMissingKey(t *testing.T) {
+	summary := map[string]interface{}{
+		"findings": []interface{}{"a", "b", "c", "d", "e", "f"},
+	}
+	assert.Len(t, extractTopFindings(summary), 5)
+	assert.Nil(t, extractTopFindings(map[string]interface{}{}))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseUpdatedAfterCursor reads the updated_after (unix milliseconds) and
+// since_id query params shared by listJobs and listCommitHistory's
+// incremental-polling mode. hasCursor is false when updated_after wasn't
+// supplied at all, in which case callers should fall back to their default
+// "last N rows" behavior.
+func parseUpdatedAfterCursor(c *gin.Context) (cursor time.Time, sinceID string, hasCursor bool, err error) {
+	raw := c.Query("updated_after")
+	if raw == "" {
+		return time.Time{}, "", false, nil
+	}
+
+	ms, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false, fmt.Errorf("invalid updated_after: %w", err)
+	}
+
+	return time.UnixMilli(ms).UTC(), c.Query("since_id"), true, nil
+}
+
+// cursorETag derives an ETag from the max cursor value in a page of
+// incrementally-polled results, so a client can conditionally GET with
+// If-None-Match and get back 304 when nothing changed.
+func cursorETag(maxUpdated time.Time, lastID string) string {
+	return fmt.Sprintf(`"%d-%s"`, maxUpdated.UnixMilli(), lastID)
+}
+
+// respondNotModified writes 304 when the request's If-None-Match header
+// matches etag, reporting whether it did so the caller can skip building
+// the rest of the response.
+func respondNotModified(c *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	if c.GetHeader("If-None-Match") != etag {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
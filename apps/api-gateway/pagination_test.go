@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorETag_IsDeterministic(t *testing.T) {
+	ts := time.UnixMilli(1700000000000).UTC()
+	assert.Equal(t, `"1700000000000-job-1"`, cursorETag(ts, "job-1"))
+	assert.Equal(t, cursorETag(ts, "job-1"), cursorETag(ts, "job-1"))
+}
+
+func TestCursorETag_DiffersWhenIDDiffers(t *testing.T) {
+	ts := time.UnixMilli(1700000000000).UTC()
+	assert.NotEqual(t, cursorETag(ts, "job-1"), cursorETag(ts, "job-2"))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Promotion represents the current pin of a completed analysis job to a
+// named environment for a repository.
+type Promotion struct {
+	RepoID      string    `json:"repo_id"`
+	Environment string    `json:"environment"`
+	JobID       string    `json:"job_id"`
+	PromotedAt  time.Time `json:"promoted_at"`
+	PromotedBy  string    `json:"promoted_by,omitempty"`
+}
+
+// PromotionRequest is the body of POST /api/v1/repos/:repo_id/promotions
+type PromotionRequest struct {
+	Environment string `json:"environment" binding:"required"`
+	JobID       string `json:"job_id" binding:"required"`
+	PromotedBy  string `json:"promoted_by,omitempty"`
+}
+
+// ensurePromotionsSchema creates the promotions table if migrations were not applied.
+func ensurePromotionsSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS promotions (
+			id SERIAL PRIMARY KEY,
+			repo_id VARCHAR(255) NOT NULL,
+			environment VARCHAR(64) NOT NULL,
+			job_id VARCHAR(255) NOT NULL,
+			promoted_at TIMESTAMP NOT NULL DEFAULT now(),
+			promoted_by VARCHAR(255),
+			UNIQUE(repo_id, environment)
+		);
+		CREATE INDEX IF NOT EXISTS idx_promotions_repo_id ON promotions(repo_id);
+	`)
+	return err
+}
+
+// createPromotion handles POST /api/v1/repos/:repo_id/promotions, pinning a
+// COMPLETED job as the authoritative analysis for repo_id+environment.
+func createPromotion(c *gin.Context) {
+	repoID := c.Param("repo_id")
+	if !validateUUID(repoID) {
+		validationError(c, "repo_id", "Invalid repository ID")
+		return
+	}
+
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var status, repoURL string
+	err := db.QueryRow("SELECT status, repo_url FROM analysis_jobs WHERE job_id = $1", req.JobID).Scan(&status, &repoURL)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up job", "details": err.Error()})
+		return
+	}
+
+	if generateRepoID(repoURL) != repoID {
+		validationError(c, "job_id", "Job does not belong to this repository")
+		return
+	}
+	if status != "COMPLETED" {
+		validationError(c, "job_id", "Job must be COMPLETED to be promoted")
+		return
+	}
+
+	promotedAt := time.Now().UTC()
+	_, err = db.Exec(`
+		INSERT INTO promotions (repo_id, environment, job_id, promoted_at, promoted_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (repo_id, environment)
+		DO UPDATE SET job_id = $3, promoted_at = $4, promoted_by = $5
+	`, repoID, req.Environment, req.JobID, promotedAt, req.PromotedBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record promotion", "details": err.Error()})
+		return
+	}
+
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:        "promoted",
+		JobID:       req.JobID,
+		RepoID:      repoID,
+		Environment: req.Environment,
+		Timestamp:   promotedAt,
+	})
+
+	go rerunEnvironmentPostProcessors(repoID, repoURL, req.Environment, req.JobID)
+
+	c.JSON(http.StatusOK, Promotion{
+		RepoID:      repoID,
+		Environment: req.Environment,
+		JobID:       req.JobID,
+		PromotedAt:  promotedAt,
+		PromotedBy:  req.PromotedBy,
+	})
+}
+
+// listPromotions handles GET /api/v1/repos/:repo_id/promotions, returning the
+// current pin per environment for a repository.
+func listPromotions(c *gin.Context) {
+	repoID := c.Param("repo_id")
+	if !validateUUID(repoID) {
+		validationError(c, "repo_id", "Invalid repository ID")
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT repo_id, environment, job_id, promoted_at, promoted_by
+		FROM promotions
+		WHERE repo_id = $1
+		ORDER BY environment ASC
+	`, repoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list promotions", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	promotions := []Promotion{}
+	for rows.Next() {
+		var p Promotion
+		var promotedBy sql.NullString
+		if err := rows.Scan(&p.RepoID, &p.Environment, &p.JobID, &p.PromotedAt, &promotedBy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan promotion", "details": err.Error()})
+			return
+		}
+		p.PromotedBy = promotedBy.String
+		promotions = append(promotions, p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotions": promotions})
+}
+
+// resolvePromotedJobID looks up the job_id currently pinned to repo_id+environment.
+func resolvePromotedJobID(repoID, environment string) (string, error) {
+	var jobID string
+	err := db.QueryRow(
+		"SELECT job_id FROM promotions WHERE repo_id = $1 AND environment = $2",
+		repoID, environment,
+	).Scan(&jobID)
+	return jobID, err
+}
+
+// rerunEnvironmentPostProcessors fetches the newly pinned job's graph from
+// the graph engine, fire-and-forget, and logs if that fails. The fetched
+// graph is discarded: no LLM summary or export bundle is regenerated.
+func rerunEnvironmentPostProcessors(repoID, repoURL, environment, jobID string) {
+	graphURL := getEnv("GRAPH_ENGINE_URL", "http://localhost:8000")
+	if _, _, err := fetchGraphEngineGraphForJob(graphURL, repoID, jobID, 5000); err != nil {
+		log.Printf("⚠️  Failed to re-run post-processors for %s/%s promotion: %v", repoID, environment, err)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionRequest_JSONTags(t *testing.T) {
+	var req PromotionRequest
+	err := json.Unmarshal([]byte(`{"environment":"staging","job_id":"job-1","promoted_by":"alice"}`), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", req.Environment)
+	assert.Equal(t, "job-1", req.JobID)
+	assert.Equal(t, "alice", req.PromotedBy)
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NormalizedEvent is the provider-agnostic shape every GitProvider parses
+// its own push/PR payload into, so downstream job creation never has to
+// know which forge a delivery came from.
+type NormalizedEvent struct {
+	Provider      string
+	RepoURL       string
+	Before        string
+	After         string
+	Branch        string
+	PusherName    string
+	PusherEmail   string
+	ChangedFiles  []string
+	RemovedFiles  []string
+	IsPullRequest bool
+	PRNumber      int
+	PRAction      string
+}
+
+// GitProvider abstracts over a git forge's webhook conventions: how its
+// requests are signed and how its payloads are shaped.
+type GitProvider interface {
+	// VerifySignature checks the delivery against secret using whatever
+	// scheme this provider uses (HMAC header, plain-token header, IP
+	// allowlist, ...).
+	VerifySignature(headers http.Header, body []byte, secret string) bool
+	// ParseEvent normalizes a push or pull-request payload.
+	ParseEvent(body []byte, headers http.Header) (NormalizedEvent, error)
+	Name() string
+}
+
+// isSupportedProvider reports whether name is one of the forges this
+// gateway knows how to verify and parse.
+func isSupportedProvider(name string) bool {
+	switch name {
+	case "github", "gitlab", "gitea", "bitbucket":
+		return true
+	default:
+		return false
+	}
+}
+
+func providerForName(name string) GitProvider {
+	switch name {
+	case "gitlab":
+		return gitlabProvider{}
+	case "gitea":
+		return giteaProvider{}
+	case "bitbucket":
+		return bitbucketProvider{}
+	default:
+		return githubProvider{}
+	}
+}
+
+// ensureWebhookProviderSchema adds the provider column to webhooks if an
+// older schema migration hasn't created it yet, defaulting existing rows to
+// "github" since that was the only provider before this change.
+func ensureWebhookProviderSchema() error {
+	_, err := db.Exec(`
+		ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS provider VARCHAR(32) NOT NULL DEFAULT 'github';
+	`)
+	return err
+}
+
+// githubProvider wraps the existing GitHub verification/parsing helpers so
+// GitHub fits the same GitProvider interface as the newer forges.
+type githubProvider struct{}
+
+func (githubProvider) Name() string { return "github" }
+
+func (githubProvider) VerifySignature(headers http.Header, body []byte, secret string) bool {
+	return verifyGitHubSignature(body, headers.Get("X-Hub-Signature-256"), secret)
+}
+
+func (githubProvider) ParseEvent(body []byte, headers http.Header) (NormalizedEvent, error) {
+	switch headers.Get("X-GitHub-Event") {
+	case "pull_request":
+		var pr GitHubPullRequestPayload
+		if err := json.Unmarshal(body, &pr); err != nil {
+			return NormalizedEvent{}, err
+		}
+		return NormalizedEvent{
+			Provider:      "github",
+			RepoURL:       pr.Repository.CloneURL,
+			Branch:        pr.PullRequest.Head.Ref,
+			IsPullRequest: true,
+			PRNumber:      pr.Number,
+			PRAction:      pr.Action,
+		}, nil
+	default:
+		var push GitHubPushPayload
+		if err := json.Unmarshal(body, &push); err != nil {
+			return NormalizedEvent{}, err
+		}
+		return NormalizedEvent{
+			Provider:     "github",
+			RepoURL:      push.Repository.CloneURL,
+			Before:       push.Before,
+			After:        push.After,
+			Branch:       extractBranchName(push.Ref),
+			PusherName:   push.Pusher.Name,
+			PusherEmail:  push.Pusher.Email,
+			ChangedFiles: collectChangedFiles(push.Commits),
+			RemovedFiles: collectRemovedFiles(push.Commits),
+		}, nil
+	}
+}
+
+// gitlabPushPayload covers the fields this gateway needs from a GitLab
+// "Push Hook" or "Merge Request Hook" event.
+type gitlabPushPayload struct {
+	ObjectKind string `json:"object_kind"`
+	Ref        string `json:"ref"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	UserName   string `json:"user_name"`
+	UserEmail  string `json:"user_email"`
+	Project    struct {
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"project"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Action       string `json:"action"`
+		SourceBranch string `json:"source_branch"`
+	} `json:"object_attributes"`
+}
+
+type gitlabProvider struct{}
+
+func (gitlabProvider) Name() string { return "gitlab" }
+
+// VerifySignature compares GitLab's X-Gitlab-Token header against the
+// configured secret directly (GitLab does not HMAC-sign payloads).
+func (gitlabProvider) VerifySignature(headers http.Header, _ []byte, secret string) bool {
+	if secret == "" {
+		return true
+	}
+	token := headers.Get("X-Gitlab-Token")
+	return hmac.Equal([]byte(token), []byte(secret))
+}
+
+func (gitlabProvider) ParseEvent(body []byte, _ http.Header) (NormalizedEvent, error) {
+	var payload gitlabPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return NormalizedEvent{}, err
+	}
+
+	if payload.ObjectKind == "merge_request" {
+		return NormalizedEvent{
+			Provider:      "gitlab",
+			RepoURL:       payload.Project.GitHTTPURL,
+			Branch:        payload.ObjectAttributes.SourceBranch,
+			IsPullRequest: true,
+			PRNumber:      payload.ObjectAttributes.IID,
+			PRAction:      payload.ObjectAttributes.Action,
+		}, nil
+	}
+
+	var changed, removed []string
+	for _, commit := range payload.Commits {
+		changed = append(changed, commit.Added...)
+		changed = append(changed, commit.Modified...)
+		removed = append(removed, commit.Removed...)
+	}
+
+	return NormalizedEvent{
+		Provider:     "gitlab",
+		RepoURL:      payload.Project.GitHTTPURL,
+		Before:       payload.Before,
+		After:        payload.After,
+		Branch:       extractBranchName(payload.Ref),
+		PusherName:   payload.UserName,
+		PusherEmail:  payload.UserEmail,
+		ChangedFiles: changed,
+		RemovedFiles: removed,
+	}, nil
+}
+
+// giteaPushPayload mirrors GitHub's push payload shape closely enough to
+// reuse most of its field names; Gitea's API is intentionally GitHub-like.
+type giteaPushPayload struct {
+	Ref        string `json:"ref"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+	Pusher struct {
+		Login string `json:"login"`
+		Email string `json:"email"`
+	} `json:"pusher"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+type giteaProvider struct{}
+
+func (giteaProvider) Name() string { return "gitea" }
+
+func (giteaProvider) VerifySignature(headers http.Header, body []byte, secret string) bool {
+	if secret == "" {
+		return true
+	}
+	signature := headers.Get("X-Gitea-Signature")
+	if signature == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+func (giteaProvider) ParseEvent(body []byte, _ http.Header) (NormalizedEvent, error) {
+	var payload giteaPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return NormalizedEvent{}, err
+	}
+
+	var changed, removed []string
+	for _, commit := range payload.Commits {
+		changed = append(changed, commit.Added...)
+		changed = append(changed, commit.Modified...)
+		removed = append(removed, commit.Removed...)
+	}
+
+	return NormalizedEvent{
+		Provider:     "gitea",
+		RepoURL:      payload.Repository.CloneURL,
+		Before:       payload.Before,
+		After:        payload.After,
+		Branch:       extractBranchName(payload.Ref),
+		PusherName:   payload.Pusher.Login,
+		PusherEmail:  payload.Pusher.Email,
+		ChangedFiles: changed,
+		RemovedFiles: removed,
+	}, nil
+}
+
+// bitbucketPushPayload covers the subset of Bitbucket Cloud's "repo:push"
+// event this gateway consumes.
+type bitbucketPushPayload struct {
+	Push struct {
+		Changes []struct {
+			New struct {
+				Name   string `json:"name"`
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			} `json:"new"`
+			Old struct {
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			} `json:"old"`
+		} `json:"changes"`
+	} `json:"push"`
+	Repository struct {
+		Links struct {
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+		} `json:"links"`
+	} `json:"repository"`
+	Actor struct {
+		Username string `json:"username"`
+	} `json:"actor"`
+}
+
+type bitbucketProvider struct{}
+
+func (bitbucketProvider) Name() string { return "bitbucket" }
+
+// VerifySignature checks the optional shared secret sent as a bearer token
+// in the Authorization header. Bitbucket Cloud does not sign payloads;
+// operators that need stronger assurance should additionally restrict this
+// route to Bitbucket's published webhook IP ranges at the proxy/firewall
+// layer, since net/http does not expose the original client IP consistently
+// behind proxies.
+func (bitbucketProvider) VerifySignature(headers http.Header, _ []byte, secret string) bool {
+	if secret == "" {
+		return true
+	}
+	authHeader := headers.Get("Authorization")
+	expected := "Bearer " + secret
+	return hmac.Equal([]byte(authHeader), []byte(expected))
+}
+
+func (bitbucketProvider) ParseEvent(body []byte, _ http.Header) (NormalizedEvent, error) {
+	var payload bitbucketPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return NormalizedEvent{}, err
+	}
+
+	var repoURL string
+	for _, clone := range payload.Repository.Links.Clone {
+		if clone.Name == "https" {
+			repoURL = clone.Href
+			break
+		}
+	}
+
+	event := NormalizedEvent{
+		Provider:   "bitbucket",
+		RepoURL:    repoURL,
+		PusherName: payload.Actor.Username,
+	}
+	if len(payload.Push.Changes) > 0 {
+		change := payload.Push.Changes[len(payload.Push.Changes)-1]
+		event.Branch = change.New.Name
+		event.Before = change.Old.Target.Hash
+		event.After = change.New.Target.Hash
+	}
+	return event, nil
+}
+
+// handleProviderWebhook is the shared entrypoint for every non-GitHub
+// webhook route; it verifies the signature, normalizes the payload, and
+// hands it to submitWebhookAnalysisJob — the same jobTracker-coalescing path
+// GitHub's own handlePullRequestEvent uses — so a burst of deliveries for
+// the same ref merges instead of each queuing its own job, matching the
+// GitHub path's dedup/supersede guarantees instead of the bypass a direct
+// createWebhookAnalysisJob call would give every other provider.
+func handleProviderWebhook(provider GitProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, WebhookResponse{Status: "error", Message: "Failed to read request body"})
+			return
+		}
+
+		secret := resolveProviderWebhookSecret(provider.Name(), body)
+		if !provider.VerifySignature(c.Request.Header, body, secret) {
+			log.Printf("❌ %s webhook: invalid signature from IP %s", provider.Name(), c.ClientIP())
+			c.JSON(http.StatusUnauthorized, WebhookResponse{Status: "error", Message: "Invalid signature"})
+			return
+		}
+
+		event, err := provider.ParseEvent(body, c.Request.Header)
+		if err != nil {
+			log.Printf("❌ %s webhook: failed to parse payload: %v", provider.Name(), err)
+			c.JSON(http.StatusBadRequest, WebhookResponse{Status: "error", Message: "Invalid webhook payload"})
+			return
+		}
+
+		if event.RepoURL == "" {
+			c.JSON(http.StatusOK, WebhookResponse{Status: "ignored", Message: "Event carried no repository URL"})
+			return
+		}
+
+		allChanged := append(append([]string{}, event.ChangedFiles...), event.RemovedFiles...)
+		if !event.IsPullRequest && !hasAnalyzableFiles(allChanged) {
+			c.JSON(http.StatusOK, WebhookResponse{Status: "skipped", Message: "No analyzable code files were changed"})
+			return
+		}
+
+		trigger := "push"
+		if event.IsPullRequest {
+			trigger = "pull_request"
+		}
+
+		jobID, merged, dropped, err := submitWebhookAnalysisJob(event.RepoURL, event.Branch, trigger, event.Before, event.After, event.ChangedFiles, event.RemovedFiles)
+		if err != nil {
+			log.Printf("❌ %s webhook: failed to create analysis job: %v", provider.Name(), err)
+			c.JSON(http.StatusInternalServerError, WebhookResponse{Status: "error", Message: "Failed to create analysis job"})
+			return
+		}
+
+		if dropped {
+			c.JSON(http.StatusOK, WebhookResponse{Status: "duplicate", Message: fmt.Sprintf("%s delivery ignored as a near-duplicate", provider.Name())})
+			return
+		}
+		if merged {
+			c.JSON(http.StatusOK, WebhookResponse{Status: "debounced", Message: fmt.Sprintf("Merged into its ref's running analysis via %s", provider.Name())})
+			return
+		}
+
+		if event.IsPullRequest {
+			go publishPendingCommitStatus(event.RepoURL, event.After, jobID)
+		}
+
+		c.JSON(http.StatusOK, WebhookResponse{Status: "queued", Message: fmt.Sprintf("Analysis job created via %s", provider.Name()), JobID: jobID})
+	}
+}
+
+// resolveProviderWebhookSecret looks up the stored secret for the webhook
+// matching this provider and the payload's repository URL, mirroring
+// resolveWebhookSecret but parameterized by provider.
+func resolveProviderWebhookSecret(providerName string, body []byte) string {
+	event, err := providerForName(providerName).ParseEvent(body, http.Header{})
+	if err != nil || event.RepoURL == "" {
+		return ""
+	}
+
+	repoURL := normalizeRepoURL(event.RepoURL)
+	var secret sql.NullString
+	err = db.QueryRow(`
+		SELECT w.secret
+		FROM webhooks w
+		JOIN repositories r ON w.repo_id = r.id
+		WHERE r.url = $1 AND w.provider = $2 AND w.active = true
+		ORDER BY w.id DESC
+		LIMIT 1
+	`, repoURL, providerName).Scan(&secret)
+	if err != nil || !secret.Valid {
+		return ""
+	}
+	return secret.String
+}
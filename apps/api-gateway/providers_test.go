@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupportedProvider(t *testing.T) {
+	assert.True(t, isSupportedProvider("github"))
+	assert.True(t, isSupportedProvider("gitlab"))
+	assert.True(t, isSupportedProvider("gitea"))
+	assert.True(t, isSupportedProvider("bitbucket"))
+	assert.False(t, isSupportedProvider("svn"))
+	assert.False(t, isSupportedProvider(""))
+}
+
+func TestGitLabProvider_VerifySignature(t *testing.T) {
+	p := gitlabProvider{}
+
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Token", "s3cret")
+	assert.True(t, p.VerifySignature(headers, nil, "s3cret"))
+
+	headers.Set("X-Gitlab-Token", "wrong")
+	assert.False(t, p.VerifySignature(headers, nil, "s3cret"))
+
+	// No configured secret means verification is skipped (same as GitHub's
+	// "warn and allow" behavior in development).
+	assert.True(t, p.VerifySignature(http.Header{}, nil, ""))
+}
+
+func TestGitLabProvider_ParseEvent_Push(t *testing.T) {
+	body := []byte(`{
+		"object_kind": "push",
+		"ref": "refs/heads/main",
+		"before": "aaa",
+		"after": "bbb",
+		"user_name": "octocat",
+		"user_email": "octocat@example.com",
+		"project": {"git_http_url": "https://gitlab.com/octocat/widgets.git"},
+		"commits": [{"added": ["a.go"], "modified": ["b.go"], "removed": ["c.go"]}]
+	}`)
+
+	event, err := gitlabProvider{}.ParseEvent(body, http.Header{})
+	assert.NoError(t, err)
+	assert.Equal(t, "gitlab", event.Provider)
+	assert.Equal(t, "https://gitlab.com/octocat/widgets.git", event.RepoURL)
+	assert.Equal(t, "main", event.Branch)
+	assert.False(t, event.IsPullRequest)
+	assert.ElementsMatch(t, []string{"a.go", "b.go"}, event.ChangedFiles)
+	assert.ElementsMatch(t, []string{"c.go"}, event.RemovedFiles)
+}
+
+func TestGitLabProvider_ParseEvent_MergeRequest(t *testing.T) {
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"git_http_url": "https://gitlab.com/octocat/widgets.git"},
+		"object_attributes": {"iid": 7, "action": "open", "source_branch": "feature-x"}
+	}`)
+
+	event, err := gitlabProvider{}.ParseEvent(body, http.Header{})
+	assert.NoError(t, err)
+	assert.True(t, event.IsPullRequest)
+	assert.Equal(t, 7, event.PRNumber)
+	assert.Equal(t, "open", event.PRAction)
+	assert.Equal(t, "feature-x", event.Branch)
+}
+
+func TestGiteaProvider_VerifySignature(t *testing.T) {
+	p := giteaProvider{}
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	validSig := hex.EncodeToString(mac.Sum(nil))
+
+	headers := http.Header{}
+	headers.Set("X-Gitea-Signature", validSig)
+	assert.True(t, p.VerifySignature(headers, body, "s3cret"))
+
+	headers.Set("X-Gitea-Signature", "deadbeef")
+	assert.False(t, p.VerifySignature(headers, body, "s3cret"))
+
+	assert.False(t, p.VerifySignature(http.Header{}, body, "s3cret"), "missing signature header must fail when a secret is configured")
+}
+
+func TestGiteaProvider_ParseEvent(t *testing.T) {
+	body := []byte(`{
+		"ref": "refs/heads/develop",
+		"before": "aaa",
+		"after": "bbb",
+		"repository": {"clone_url": "https://gitea.example.com/octocat/widgets.git"},
+		"pusher": {"login": "octocat", "email": "octocat@example.com"},
+		"commits": [{"added": ["a.go"], "modified": [], "removed": []}]
+	}`)
+
+	event, err := giteaProvider{}.ParseEvent(body, http.Header{})
+	assert.NoError(t, err)
+	assert.Equal(t, "gitea", event.Provider)
+	assert.Equal(t, "develop", event.Branch)
+	assert.Equal(t, "octocat", event.PusherName)
+	assert.Equal(t, []string{"a.go"}, event.ChangedFiles)
+}
+
+func TestBitbucketProvider_VerifySignature(t *testing.T) {
+	p := bitbucketProvider{}
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer s3cret")
+	assert.True(t, p.VerifySignature(headers, nil, "s3cret"))
+
+	headers.Set("Authorization", "Bearer wrong")
+	assert.False(t, p.VerifySignature(headers, nil, "s3cret"))
+
+	assert.True(t, p.VerifySignature(http.Header{}, nil, ""), "no configured secret means no auth check")
+}
+
+func TestBitbucketProvider_ParseEvent(t *testing.T) {
+	body := []byte(`{
+		"push": {"changes": [{
+			"new": {"name": "main", "target": {"hash": "bbb"}},
+			"old": {"target": {"hash": "aaa"}}
+		}]},
+		"repository": {"links": {"clone": [
+			{"name": "ssh", "href": "git@bitbucket.org:octocat/widgets.git"},
+			{"name": "https", "href": "https://bitbucket.org/octocat/widgets.git"}
+		]}},
+		"actor": {"username": "octocat"}
+	}`)
+
+	event, err := bitbucketProvider{}.ParseEvent(body, http.Header{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://bitbucket.org/octocat/widgets.git", event.RepoURL, "should prefer the https clone link over ssh")
+	assert.Equal(t, "main", event.Branch)
+	assert.Equal(t, "aaa", event.Before)
+	assert.Equal(t, "bbb", event.After)
+	assert.Equal(t, "octocat", event.PusherName)
+}
+
+func TestProviderForName_DefaultsToGitHub(t *testing.T) {
+	assert.Equal(t, "github", providerForName("unknown").Name())
+	assert.Equal(t, "gitlab", providerForName("gitlab").Name())
+}
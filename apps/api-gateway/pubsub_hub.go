@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// instanceID uniquely identifies this gateway process so the pub/sub
+// subscriber can recognize and skip messages it published itself.
+var instanceID = uuid.New().String()
+
+const (
+	wsJobChannelPrefix  = "archmind:job:"
+	wsRepoChannelPrefix = "archmind:repo:"
+)
+
+// wsPubSubEnabled reports whether the hub should fan JobUpdates out over
+// Redis pub/sub so other gateway instances' locally connected clients
+// receive them too. Set WS_PUBSUB=0 to fall back to pure in-memory
+// delivery, e.g. single-instance deployments or tests without Redis.
+func wsPubSubEnabled() bool {
+	return strings.TrimSpace(getEnv("WS_PUBSUB", "1")) != "0"
+}
+
+// publish fans update out to Redis so sibling gateway instances can deliver
+// it to their own locally connected clients. It is a no-op when pub/sub is
+// disabled or the update has neither a JobID nor a RepoID to route on.
+func (h *WebSocketHub) publish(update JobUpdate) {
+	if !wsPubSubEnabled() {
+		return
+	}
+
+	payload, err := json.Marshal(update)
+	if err != nil {
+		log.Printf("⚠️  WebSocket pub/sub: failed to marshal update: %v", err)
+		return
+	}
+
+	if update.JobID != "" {
+		if err := redisClient.Publish(ctx, wsJobChannelPrefix+update.JobID, payload).Err(); err != nil {
+			log.Printf("⚠️  WebSocket pub/sub: failed to publish job update: %v", err)
+		}
+	}
+	if update.RepoID != "" {
+		if err := redisClient.Publish(ctx, wsRepoChannelPrefix+update.RepoID, payload).Err(); err != nil {
+			log.Printf("⚠️  WebSocket pub/sub: failed to publish repo update: %v", err)
+		}
+	}
+}
+
+// subscribeLoop subscribes to every job/repo update channel across the
+// gateway fleet and injects messages this instance didn't originate into
+// the local broadcast path, so locally connected clients get delivery
+// regardless of which instance produced the update.
+func (h *WebSocketHub) subscribeLoop() {
+	pubsub := redisClient.PSubscribe(ctx, wsJobChannelPrefix+"*", wsRepoChannelPrefix+"*")
+	defer pubsub.Close()
+
+	log.Println("📡 WebSocket hub: subscribed to distributed job/repo updates")
+
+	for msg := range pubsub.Channel() {
+		var update JobUpdate
+		if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+			log.Printf("⚠️  WebSocket pub/sub: failed to decode update: %v", err)
+			continue
+		}
+
+		// Skip messages we published ourselves; our local broadcast path
+		// already delivered them to our own clients.
+		if update.OriginInstance == instanceID {
+			continue
+		}
+
+		h.broadcast <- update
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSPubSubEnabled(t *testing.T) {
+	original, hadOriginal := os.LookupEnv("WS_PUBSUB")
+	defer func() {
+		if hadOriginal {
+			os.Setenv("WS_PUBSUB", original)
+		} else {
+			os.Unsetenv("WS_PUBSUB")
+		}
+	}()
+
+	os.Unsetenv("WS_PUBSUB")
+	assert.True(t, wsPubSubEnabled(), "defaults to enabled")
+
+	os.Setenv("WS_PUBSUB", "0")
+	assert.False(t, wsPubSubEnabled())
+
+	os.Setenv("WS_PUBSUB", "1")
+	assert.True(t, wsPubSubEnabled())
+}
+
+func TestWSChannelPrefixes_AreDistinct(t *testing.T) {
+	assert.Equal(t, "archmind:job:42", wsJobChannelPrefix+"42")
+	assert.Equal(t, "archmind:repo:42", wsRepoChannelPrefix+"42")
+	assert.NotEqual(t, wsJobChannelPrefix, wsRepoChannelPrefix)
+}
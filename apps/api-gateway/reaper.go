@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// Reaper periodically recovers analysis jobs whose worker stopped sending
+// heartbeats, so a crashed worker never leaves a job stuck in PROCESSING
+// forever.
+type Reaper struct {
+	// Interval controls how often the reaper scans for stale jobs.
+	Interval time.Duration
+	// HeartbeatTimeout is how long a job can go without a heartbeat before
+	// it is considered abandoned. Workers are expected to UPDATE
+	// heartbeat_at every Interval/3 seconds or so while PROCESSING.
+	HeartbeatTimeout time.Duration
+	// MaxAttempts records the configured attempt limit. reapOnce does not
+	// read it: each row's own max_attempts column decides when a stale job
+	// is moved to FAILED instead of being requeued.
+	MaxAttempts int
+}
+
+// NewReaper builds a Reaper with the given heartbeat timeout and max
+// attempts, reaping on a schedule of timeout/3 (so stale jobs are noticed
+// promptly without scanning constantly).
+func NewReaper(heartbeatTimeout time.Duration, maxAttempts int) *Reaper {
+	interval := heartbeatTimeout / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Reaper{
+		Interval:         interval,
+		HeartbeatTimeout: heartbeatTimeout,
+		MaxAttempts:      maxAttempts,
+	}
+}
+
+// Run blocks, reaping stale jobs on Interval until ctx-like stop channel is
+// closed. It is safe to run concurrently with other reaper instances (e.g.
+// multiple gateway replicas) because the recovery itself is a single SQL
+// statement guarded by row status.
+func (r *Reaper) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.reapOnce(); err != nil {
+				log.Printf("⚠️  Reaper: scan failed: %v", err)
+			}
+		}
+	}
+}
+
+// reapOnce requeues or fails every PROCESSING job whose heartbeat is older
+// than HeartbeatTimeout. The WHERE clause on status='PROCESSING' makes each
+// row transition idempotent: once a row is moved to QUEUED or FAILED, a
+// concurrent reaper run will simply not match it again.
+func (r *Reaper) reapOnce() error {
+	requeued, err := db.Exec(`
+		UPDATE analysis_jobs
+		SET status = 'QUEUED',
+			attempt_count = attempt_count + 1,
+			last_error = 'worker died',
+			updated_at = now()
+		WHERE status = 'PROCESSING'
+		  AND heartbeat_at < now() - ($1 || ' seconds')::interval
+		  AND attempt_count + 1 < max_attempts
+	`, int(r.HeartbeatTimeout.Seconds()))
+	if err != nil {
+		return err
+	}
+	if n, _ := requeued.RowsAffected(); n > 0 {
+		log.Printf("♻️  Reaper: requeued %d stale job(s)", n)
+	}
+
+	failed, err := db.Exec(`
+		UPDATE analysis_jobs
+		SET status = 'FAILED',
+			attempt_count = attempt_count + 1,
+			last_error = 'worker died',
+			completed_at = now(),
+			updated_at = now()
+		WHERE status = 'PROCESSING'
+		  AND heartbeat_at < now() - ($1 || ' seconds')::interval
+		  AND attempt_count + 1 >= max_attempts
+	`, int(r.HeartbeatTimeout.Seconds()))
+	if err != nil {
+		return err
+	}
+	if n, _ := failed.RowsAffected(); n > 0 {
+		log.Printf("💀 Reaper: failed %d job(s) at max attempts", n)
+	}
+
+	return nil
+}
+
+// ensureReaperSchema adds the columns the reaper needs if an older schema
+// migration hasn't created them yet.
+func ensureReaperSchema() error {
+	_, err := db.Exec(`
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP DEFAULT now();
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS attempt_count INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 3;
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS last_error TEXT;
+	`)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests mirror the decision logic embedded in reapOnce's SQL (see
+// reaper.go): a PROCESSING job is left alone while its heartbeat is recent,
+// requeued to QUEUED while attempts remain, and moved to FAILED once
+// max_attempts would be exceeded.
+
+// reapDecision mirrors the WHERE/SET logic in reapOnce's two UPDATE
+// statements so it can be exercised without a live Postgres connection.
+func reapDecision(heartbeatAge, timeout time.Duration, attemptCount, maxAttempts int) string {
+	if heartbeatAge < timeout {
+		return "" // healthy, not reaped
+	}
+	if attemptCount+1 < maxAttempts {
+		return "QUEUED"
+	}
+	return "FAILED"
+}
+
+func TestReapDecision_HealthyJobNotReaped(t *testing.T) {
+	decision := reapDecision(5*time.Second, 90*time.Second, 0, 3)
+	assert.Equal(t, "", decision, "a job with a recent heartbeat should not be touched")
+}
+
+func TestReapDecision_StaleJobRequeued(t *testing.T) {
+	decision := reapDecision(120*time.Second, 90*time.Second, 0, 3)
+	assert.Equal(t, "QUEUED", decision, "a stale job with attempts remaining should be requeued")
+}
+
+func TestReapDecision_MaxAttemptsMovesToFailed(t *testing.T) {
+	decision := reapDecision(120*time.Second, 90*time.Second, 2, 3)
+	assert.Equal(t, "FAILED", decision, "a stale job at max attempts should be failed, not requeued forever")
+}
+
+func TestReapDecision_IdempotentUnderConcurrentExecution(t *testing.T) {
+	// Two concurrent reaper runs evaluating the same (now-stale) snapshot
+	// must agree on the outcome; the SQL's status='PROCESSING' guard then
+	// ensures only the first UPDATE actually matches a row.
+	first := reapDecision(200*time.Second, 90*time.Second, 1, 3)
+	second := reapDecision(200*time.Second, 90*time.Second, 1, 3)
+	assert.Equal(t, first, second, "reap decision must be deterministic for concurrent runs")
+}
+
+func TestValidateStatusTransition_ReapOnlyAllowedForReaper(t *testing.T) {
+	assert.True(t, validateStatusTransition("PROCESSING", "QUEUED", TransitionReasonReaper))
+	assert.False(t, validateStatusTransition("PROCESSING", "QUEUED", TransitionReasonAPI))
+}
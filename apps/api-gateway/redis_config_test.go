@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAndTrim(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"whitespace_only", "   ", nil},
+		{"single", "10.0.0.1:26379", []string{"10.0.0.1:26379"}},
+		{"multiple_with_spaces", "10.0.0.1:26379, 10.0.0.2:26379 ,10.0.0.3:26379", []string{"10.0.0.1:26379", "10.0.0.2:26379", "10.0.0.3:26379"}},
+		{"drops_empty_entries", "10.0.0.1:26379,,10.0.0.2:26379", []string{"10.0.0.1:26379", "10.0.0.2:26379"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := splitAndTrim(tc.in)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("splitAndTrim(%q) = %#v, want %#v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
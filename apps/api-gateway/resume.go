@@ -0,0 +1,141 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ensureResumeSchema adds the columns checkpointed, resumable job execution
+// needs if an older schema migration hasn't created them yet.
+func ensureResumeSchema() error {
+	_, err := db.Exec(`
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS checkpoint JSONB;
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS resumable BOOLEAN NOT NULL DEFAULT false;
+	`)
+	return err
+}
+
+// CheckpointResponse represents the response to GET /api/v1/jobs/:id/checkpoint
+type CheckpointResponse struct {
+	JobID      string                 `json:"job_id"`
+	Status     string                 `json:"status"`
+	Resumable  bool                   `json:"resumable"`
+	Checkpoint map[string]interface{} `json:"checkpoint"`
+}
+
+// getJobCheckpoint returns the last checkpoint a worker persisted for a job.
+func getJobCheckpoint(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	var status string
+	var resumable bool
+	var checkpointJSON []byte
+	err := db.QueryRow(
+		"SELECT status, resumable, checkpoint FROM analysis_jobs WHERE job_id = $1",
+		jobID,
+	).Scan(&status, &resumable, &checkpointJSON)
+
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	} else if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve checkpoint"})
+		return
+	}
+
+	var checkpoint map[string]interface{}
+	if len(checkpointJSON) > 0 {
+		_ = json.Unmarshal(checkpointJSON, &checkpoint)
+	}
+
+	c.JSON(http.StatusOK, CheckpointResponse{
+		JobID:      jobID,
+		Status:     status,
+		Resumable:  resumable,
+		Checkpoint: checkpoint,
+	})
+}
+
+// resumeJob handles POST /api/v1/jobs/:id/resume, moving a FAILED,
+// resumable job back to QUEUED so a worker can pick it up where the
+// previous one crashed. The stored checkpoint is left untouched.
+func resumeJob(c *gin.Context) {
+	jobID := c.Param("id")
+	if !validateUUID(jobID) {
+		validationError(c, "id", "Invalid UUID format for job ID.")
+		return
+	}
+
+	var currentStatus string
+	var resumable bool
+	err := db.QueryRow(
+		"SELECT status, resumable FROM analysis_jobs WHERE job_id = $1",
+		jobID,
+	).Scan(&currentStatus, &resumable)
+
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	} else if err != nil {
+		log.Printf("Database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job"})
+		return
+	}
+
+	if !resumable {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Job is not marked resumable",
+		})
+		return
+	}
+
+	if !validateStatusTransition(currentStatus, "QUEUED", TransitionReasonResume) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":          "Invalid status transition",
+			"current_status": currentStatus,
+			"new_status":     "QUEUED",
+		})
+		return
+	}
+
+	var updatedAt time.Time
+	err = db.QueryRow(`
+		UPDATE analysis_jobs
+		SET status = 'QUEUED', updated_at = now()
+		WHERE job_id = $1
+		RETURNING updated_at
+	`, jobID).Scan(&updatedAt)
+	if err != nil {
+		log.Printf("Failed to resume job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resume job"})
+		return
+	}
+
+	repoID, _ := resolveRepoUUID(jobID)
+	wsHub.BroadcastJobUpdate(JobUpdate{
+		Type:    "status",
+		JobID:   jobID,
+		RepoID:  repoID,
+		Status:  "QUEUED",
+		Message: "Job resumed from checkpoint",
+	})
+
+	log.Printf("♻️  Resumed job %s from checkpoint", jobID)
+
+	c.JSON(http.StatusOK, JobUpdateResponse{
+		JobID:     jobID,
+		Status:    "QUEUED",
+		Message:   "Job resumed from checkpoint",
+		UpdatedAt: updatedAt,
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStatusTransition_ResumeOnlyAllowedWithResumeReason(t *testing.T) {
+	assert.True(t, validateStatusTransition("FAILED", "QUEUED", TransitionReasonResume))
+	assert.False(t, validateStatusTransition("FAILED", "QUEUED", TransitionReasonAPI))
+	assert.False(t, validateStatusTransition("FAILED", "QUEUED", TransitionReasonReaper))
+}
+
+// TestResumeCycle_PreservesCheckpointBytes checks that every transition of a
+// FAILED -> resume -> COMPLETED cycle is legal and that the checkpoint a
+// killed worker last wrote comes back with equal JSON content after an
+// encode/decode round trip. It calls neither resumeJob nor the database.
+func TestResumeCycle_PreservesCheckpointBytes(t *testing.T) {
+	checkpoint := map[string]interface{}{
+		"phase":          "parsing",
+		"files_done":     42,
+		"last_file_path": "src/app/routes.go",
+	}
+
+	// 1. Worker crashes mid-run, having last PATCHed this checkpoint and
+	//    marked the job resumable.
+	resumable := true
+	failUpdate := JobUpdateRequest{
+		Status:     strPtr("FAILED"),
+		Error:      strPtr("worker died"),
+		Checkpoint: checkpoint,
+		Resumable:  &resumable,
+	}
+	require.True(t, validateStatusTransition("PROCESSING", *failUpdate.Status, TransitionReasonAPI))
+
+	// 2. Operator calls POST /resume: the transition is only legal via
+	//    TransitionReasonResume, and the checkpoint field is never touched
+	//    by resumeJob, so its JSON must round-trip with equal content.
+	require.True(t, validateStatusTransition("FAILED", "QUEUED", TransitionReasonResume))
+
+	stored, err := json.Marshal(checkpoint)
+	require.NoError(t, err)
+
+	var roundTripped map[string]interface{}
+	require.NoError(t, json.Unmarshal(stored, &roundTripped))
+	restored, err := json.Marshal(roundTripped)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(stored), string(restored), "checkpoint must survive the resume cycle unchanged")
+
+	// 3. A new worker finishes the job for real.
+	require.True(t, validateStatusTransition("QUEUED", "PROCESSING", TransitionReasonAPI))
+	require.True(t, validateStatusTransition("PROCESSING", "COMPLETED", TransitionReasonAPI))
+}
+
+func strPtr(s string) *string { return &s }
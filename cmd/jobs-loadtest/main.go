@@ -0,0 +1,296 @@
+// Command jobs-loadtest drives the jobs API through realistic lifecycles
+// (QUEUED -> PROCESSING -> COMPLETED/FAILED) under configurable concurrency
+// and reports per-transition latency percentiles, throughput, and errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Arch-Mind/backend/internal/jobsapi"
+	"github.com/google/uuid"
+)
+
+// Config describes a synthetic workload to drive against the jobs API.
+type Config struct {
+	Concurrency      int             `json:"concurrency"`
+	DurationSeconds  int             `json:"duration_seconds"`
+	TargetURL        string          `json:"target_url"`
+	JobTemplate      json.RawMessage `json:"job_template"`
+	TransitionScript []string        `json:"transition_script"`
+	ProgressTickMs   int             `json:"progress_tick_ms"`
+}
+
+// scenario is a named, pre-baked override applied on top of the config file
+// so common concurrency assumptions don't need to be hand-written each time.
+type scenario struct {
+	name             string
+	concurrency      int
+	durationSeconds  int
+	transitionScript []string
+}
+
+var scenarios = map[string]scenario{
+	"steady-state": {
+		name:             "steady-state",
+		concurrency:      10,
+		durationSeconds:  60,
+		transitionScript: []string{"PROCESSING", "COMPLETED"},
+	},
+	"thundering-herd": {
+		name:             "thundering-herd",
+		concurrency:      200,
+		durationSeconds:  10,
+		transitionScript: []string{"PROCESSING", "CANCELLED"},
+	},
+	"cancel-storm": {
+		name:             "cancel-storm",
+		concurrency:      50,
+		durationSeconds:  30,
+		transitionScript: []string{"PROCESSING", "CANCELLED"},
+	},
+}
+
+// transitionResult records one PATCH call's outcome for the report.
+type transitionResult struct {
+	transition string
+	latency    time.Duration
+	statusCode int
+	err        error
+}
+
+func main() {
+	configPath := flag.String("config", "", "path to JSON workload config")
+	scenarioName := flag.String("scenario", "", "pre-baked scenario name: steady-state, thundering-herd, cancel-storm")
+	reportPath := flag.String("report", "loadtest-report.json", "path to write the JSON report")
+	promPath := flag.String("prom", "loadtest-report.prom", "path to write the Prometheus text-format report")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if *scenarioName != "" {
+		s, ok := scenarios[*scenarioName]
+		if !ok {
+			log.Fatalf("unknown scenario %q", *scenarioName)
+		}
+		cfg.Concurrency = s.concurrency
+		cfg.DurationSeconds = s.durationSeconds
+		cfg.TransitionScript = s.transitionScript
+	}
+
+	if cfg.TargetURL == "" {
+		cfg.TargetURL = "http://localhost:8080"
+	}
+	if cfg.ProgressTickMs <= 0 {
+		cfg.ProgressTickMs = 250
+	}
+
+	results := run(cfg)
+	report := buildReport(results)
+
+	if err := writeJSONReport(*reportPath, report); err != nil {
+		log.Fatalf("failed to write JSON report: %v", err)
+	}
+	if err := writePrometheusReport(*promPath, report); err != nil {
+		log.Fatalf("failed to write Prometheus report: %v", err)
+	}
+
+	fmt.Printf("jobs-loadtest: %d transitions, %d errors, p50=%s p95=%s p99=%s\n",
+		report.TotalTransitions, report.TotalErrors, report.P50, report.P95, report.P99)
+}
+
+func loadConfig(path string) (Config, error) {
+	cfg := Config{
+		Concurrency:      10,
+		DurationSeconds:  30,
+		TransitionScript: []string{"PROCESSING", "COMPLETED"},
+	}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// run drives cfg.Concurrency workers for cfg.DurationSeconds, each
+// repeatedly taking a synthetic job through cfg.TransitionScript via
+// PATCH /api/v1/jobs/:id, ticking progress every ProgressTickMs.
+func run(cfg Config) []transitionResult {
+	var (
+		mu      sync.Mutex
+		results []transitionResult
+		wg      sync.WaitGroup
+	)
+
+	deadline := time.Now().Add(time.Duration(cfg.DurationSeconds) * time.Second)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for w := 0; w < cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				jobID := uuid.New().String()
+				progress := 0
+				for _, status := range cfg.TransitionScript {
+					progress += rand.Intn(40) + 10
+					if progress > 100 {
+						progress = 100
+					}
+					req := jobsapi.JobUpdateRequest{
+						Status:   &status,
+						Progress: &progress,
+					}
+					res := patchJob(client, cfg.TargetURL, jobID, req, status)
+					mu.Lock()
+					results = append(results, res)
+					mu.Unlock()
+					time.Sleep(time.Duration(cfg.ProgressTickMs) * time.Millisecond)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+func patchJob(client *http.Client, targetURL, jobID string, req jobsapi.JobUpdateRequest, transition string) transitionResult {
+	start := time.Now()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return transitionResult{transition: transition, err: err}
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/api/v1/jobs/%s", targetURL, jobID), bytes.NewReader(body))
+	if err != nil {
+		return transitionResult{transition: transition, err: err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	latency := time.Since(start)
+	if err != nil {
+		return transitionResult{transition: transition, latency: latency, err: err}
+	}
+	defer resp.Body.Close()
+
+	var updateResp jobsapi.JobUpdateResponse
+	_ = json.NewDecoder(resp.Body).Decode(&updateResp) // schema drift here fails loudly at compile time, not at runtime
+
+	return transitionResult{transition: transition, latency: latency, statusCode: resp.StatusCode}
+}
+
+// Report is the JSON/Prometheus-serializable summary of a load-test run.
+type Report struct {
+	TotalTransitions int                 `json:"total_transitions"`
+	TotalErrors      int                 `json:"total_errors"`
+	ErrorsByStatus   map[int]int         `json:"errors_by_status"`
+	P50              time.Duration       `json:"p50_ns"`
+	P95              time.Duration       `json:"p95_ns"`
+	P99              time.Duration       `json:"p99_ns"`
+	ByTransition     map[string]Latency  `json:"by_transition"`
+}
+
+// Latency holds the percentile breakdown for a single transition name.
+type Latency struct {
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50_ns"`
+	P95   time.Duration `json:"p95_ns"`
+	P99   time.Duration `json:"p99_ns"`
+}
+
+func buildReport(results []transitionResult) Report {
+	report := Report{ErrorsByStatus: map[int]int{}, ByTransition: map[string]Latency{}}
+
+	byTransition := map[string][]time.Duration{}
+	var allLatencies []time.Duration
+
+	for _, r := range results {
+		report.TotalTransitions++
+		if r.err != nil || r.statusCode >= 300 {
+			report.TotalErrors++
+			report.ErrorsByStatus[r.statusCode]++
+			continue
+		}
+		allLatencies = append(allLatencies, r.latency)
+		byTransition[r.transition] = append(byTransition[r.transition], r.latency)
+	}
+
+	report.P50 = percentile(allLatencies, 0.50)
+	report.P95 = percentile(allLatencies, 0.95)
+	report.P99 = percentile(allLatencies, 0.99)
+
+	for transition, latencies := range byTransition {
+		report.ByTransition[transition] = Latency{
+			Count: len(latencies),
+			P50:   percentile(latencies, 0.50),
+			P95:   percentile(latencies, 0.95),
+			P99:   percentile(latencies, 0.99),
+		}
+	}
+
+	return report
+}
+
+// percentile returns the p-th percentile (0.0-1.0) of latencies using
+// nearest-rank interpolation; callers pass sorted-on-demand slices since
+// this is only called a handful of times per run.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func writeJSONReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func writePrometheusReport(path string, report Report) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP jobs_loadtest_transitions_total Total PATCH transitions attempted\n")
+	fmt.Fprintf(&buf, "# TYPE jobs_loadtest_transitions_total counter\n")
+	fmt.Fprintf(&buf, "jobs_loadtest_transitions_total %d\n", report.TotalTransitions)
+	fmt.Fprintf(&buf, "# HELP jobs_loadtest_errors_total Total failed PATCH transitions\n")
+	fmt.Fprintf(&buf, "# TYPE jobs_loadtest_errors_total counter\n")
+	fmt.Fprintf(&buf, "jobs_loadtest_errors_total %d\n", report.TotalErrors)
+	fmt.Fprintf(&buf, "# HELP jobs_loadtest_latency_seconds Transition latency percentiles\n")
+	fmt.Fprintf(&buf, "# TYPE jobs_loadtest_latency_seconds gauge\n")
+	fmt.Fprintf(&buf, "jobs_loadtest_latency_seconds{quantile=\"0.5\"} %f\n", report.P50.Seconds())
+	fmt.Fprintf(&buf, "jobs_loadtest_latency_seconds{quantile=\"0.95\"} %f\n", report.P95.Seconds())
+	fmt.Fprintf(&buf, "jobs_loadtest_latency_seconds{quantile=\"0.99\"} %f\n", report.P99.Seconds())
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		40 * time.Millisecond,
+		100 * time.Millisecond,
+	}
+
+	assert.Equal(t, 30*time.Millisecond, percentile(latencies, 0.50))
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 0.99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 0.50), "empty input should not panic")
+}
+
+func TestBuildReport_SeparatesErrorsFromSuccesses(t *testing.T) {
+	results := []transitionResult{
+		{transition: "PROCESSING", latency: 10 * time.Millisecond, statusCode: 200},
+		{transition: "COMPLETED", latency: 20 * time.Millisecond, statusCode: 200},
+		{transition: "COMPLETED", statusCode: 500},
+	}
+
+	report := buildReport(results)
+	assert.Equal(t, 3, report.TotalTransitions)
+	assert.Equal(t, 1, report.TotalErrors)
+	assert.Equal(t, 1, report.ErrorsByStatus[500])
+	assert.Equal(t, 1, report.ByTransition["PROCESSING"].Count)
+	assert.Equal(t, 1, report.ByTransition["COMPLETED"].Count, "the errored COMPLETED attempt should not count toward its latency stats")
+}
+
+func TestScenarios_HaveDistinctConcurrencyProfiles(t *testing.T) {
+	steady := scenarios["steady-state"]
+	herd := scenarios["thundering-herd"]
+	assert.Less(t, steady.concurrency, herd.concurrency, "thundering-herd should simulate a much larger burst than steady-state")
+}
@@ -0,0 +1,188 @@
+// Package github implements the GitHub OAuth2 authorization-code flow used
+// to let analyzeRepository clone private repositories on a user's behalf,
+// without pulling in golang.org/x/oauth2 (this repo has no module manager to
+// fetch it, the same constraint that led github_app.go to hand-sign its App
+// JWTs with stdlib crypto instead of a JWT library).
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	authorizeURL = "https://github.com/login/oauth/authorize"
+	tokenURL     = "https://github.com/login/oauth/access_token"
+)
+
+// DefaultScopes grants read access to a user's repos (including private
+// ones) and their org membership, the minimum GitHub scopes analyzeRepository
+// needs to clone a private repo and resolve org-level visibility.
+var DefaultScopes = []string{"repo", "read:org"}
+
+// Config holds the OAuth App credentials analyzeRepository's login/callback
+// handlers exchange codes with. ClientID/ClientSecret/RedirectURL are read
+// from GITHUB_CLIENT_ID/GITHUB_CLIENT_SECRET/GITHUB_OAUTH_REDIRECT_URL by the
+// caller, mirroring how resolveGitHubToken reads GITHUB_TOKEN directly from
+// the environment rather than a config struct threaded through main().
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Token is the access token GitHub issues in exchange for an authorization
+// code. GitHub OAuth Apps only return RefreshToken/ExpiresIn for accounts
+// enrolled in token expiration; both are zero-valued otherwise, in which
+// case the token does not expire and Refresh is never needed.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	Scope        string    `json:"scope"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the token needs refreshing before use. A zero
+// ExpiresAt means GitHub issued a non-expiring token.
+func (t Token) Expired() bool {
+	return !t.ExpiresAt.IsZero() && time.Now().After(t.ExpiresAt)
+}
+
+// AuthCodeURL builds the URL analyzeRepository's login handler redirects the
+// user to, with state round-tripped to the callback for CSRF protection.
+func (c Config) AuthCodeURL(state string) string {
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	q := url.Values{
+		"client_id":    {c.ClientID},
+		"redirect_uri": {c.RedirectURL},
+		"scope":        {strings.Join(scopes, " ")},
+		"state":        {state},
+	}
+	return authorizeURL + "?" + q.Encode()
+}
+
+// tokenErrorResponse is what GitHub returns instead of a Token on failure,
+// e.g. {"error":"bad_verification_code", ...}.
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange trades an authorization code (from the callback's "code" query
+// param) for an access token.
+func (c Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+	}
+	return c.postForm(ctx, tokenURL, form)
+}
+
+// Refresh trades a still-valid refresh token for a new access token, for
+// GitHub Apps enrolled in token expiration.
+func (c Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	return c.postForm(ctx, tokenURL, form)
+}
+
+func (c Config) postForm(ctx context.Context, endpoint string, form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw struct {
+		tokenErrorResponse
+		Token
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if raw.Error != "" {
+		return nil, fmt.Errorf("github oauth error: %s: %s", raw.Error, raw.ErrorDescription)
+	}
+	if raw.AccessToken == "" {
+		return nil, errors.New("github oauth response carried no access_token")
+	}
+
+	token := raw.Token
+	if raw.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(raw.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
+
+// TokenSource resolves and, when necessary, refreshes the access token for
+// one linked GitHub account. Save persists a refreshed token back to
+// wherever the caller is storing it (e.g. Postgres via auth_github.go).
+type TokenSource struct {
+	Config Config
+	Token  Token
+	Save   func(Token) error
+}
+
+// InjectCredential rewrites an "https://" clone URL to embed token as an
+// x-access-token credential, the form GitHub Apps and OAuth tokens both use
+// to authenticate a clone without a separate Authorization header. Non-HTTPS
+// URLs (SSH, git://) are returned unchanged since this credential form only
+// applies to HTTPS clones.
+func InjectCredential(repoURL, token string) string {
+	const prefix = "https://"
+	if len(repoURL) <= len(prefix) || repoURL[:len(prefix)] != prefix {
+		return repoURL
+	}
+	return prefix + "x-access-token:" + token + "@" + repoURL[len(prefix):]
+}
+
+// AccessToken returns a token suitable for the clone step's
+// "https://x-access-token:<token>@github.com/..." URL or an
+// "Authorization: Bearer <token>" header, refreshing first if expired.
+func (s *TokenSource) AccessToken(ctx context.Context) (string, error) {
+	if !s.Token.Expired() {
+		return s.Token.AccessToken, nil
+	}
+	if s.Token.RefreshToken == "" {
+		return "", errors.New("github token expired and no refresh_token is available")
+	}
+
+	refreshed, err := s.Config.Refresh(ctx, s.Token.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh github token: %w", err)
+	}
+	s.Token = *refreshed
+	if s.Save != nil {
+		if err := s.Save(s.Token); err != nil {
+			return "", fmt.Errorf("failed to persist refreshed github token: %w", err)
+		}
+	}
+	return s.Token.AccessToken, nil
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_AuthCodeURL_IncludesStateAndDefaultScopes(t *testing.T) {
+	c := Config{ClientID: "abc123", RedirectURL: "https://app.example.com/auth/github/callback"}
+	got := c.AuthCodeURL("xyz-state")
+
+	if !strings.Contains(got, "client_id=abc123") {
+		t.Fatalf("expected client_id in URL, got %s", got)
+	}
+	if !strings.Contains(got, "state=xyz-state") {
+		t.Fatalf("expected state in URL, got %s", got)
+	}
+	if !strings.Contains(got, "scope=repo+read%3Aorg") {
+		t.Fatalf("expected default scopes in URL, got %s", got)
+	}
+}
+
+func TestConfig_AuthCodeURL_HonorsCustomScopes(t *testing.T) {
+	c := Config{ClientID: "abc123", Scopes: []string{"repo"}}
+	got := c.AuthCodeURL("state")
+	if !strings.Contains(got, "scope=repo") || strings.Contains(got, "read%3Aorg") {
+		t.Fatalf("expected only the custom scope, got %s", got)
+	}
+}
+
+func TestToken_Expired(t *testing.T) {
+	nonExpiring := Token{AccessToken: "a"}
+	if nonExpiring.Expired() {
+		t.Fatal("token with zero ExpiresAt should never report expired")
+	}
+
+	stale := Token{AccessToken: "a", ExpiresAt: time.Now().Add(-time.Minute)}
+	if !stale.Expired() {
+		t.Fatal("expected a past ExpiresAt to report expired")
+	}
+
+	fresh := Token{AccessToken: "a", ExpiresAt: time.Now().Add(time.Hour)}
+	if fresh.Expired() {
+		t.Fatal("expected a future ExpiresAt to report not expired")
+	}
+}
+
+func TestTokenSource_AccessToken_ReturnsTokenDirectlyWhenNotExpired(t *testing.T) {
+	src := &TokenSource{Token: Token{AccessToken: "tok-123"}}
+	got, err := src.AccessToken(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "tok-123" {
+		t.Fatalf("expected tok-123, got %q", got)
+	}
+}
+
+func TestTokenSource_AccessToken_FailsWithoutRefreshTokenWhenExpired(t *testing.T) {
+	src := &TokenSource{Token: Token{AccessToken: "tok-123", ExpiresAt: time.Now().Add(-time.Hour)}}
+	_, err := src.AccessToken(nil)
+	if err == nil {
+		t.Fatal("expected an error when the token is expired and has no refresh token")
+	}
+}
@@ -0,0 +1,228 @@
+// Package gitops wraps github.com/go-git/go-git/v5, the pure-Go git
+// implementation, so the analyze pipeline can clone, inspect, and check out
+// repositories in-process instead of shelling out to an external git
+// binary. That removes the need to have git installed in the analysis
+// container/sandbox and lets clone/auth failures surface as typed errors
+// (ErrAuthenticationRequired, ErrReferenceNotFound) instead of an opaque
+// exec.ExitError.
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// ErrAuthenticationRequired means the remote rejected (or never received)
+// credentials it needed, wrapping go-git's transport.ErrAuthenticationRequired.
+var ErrAuthenticationRequired = errors.New("gitops: authentication required")
+
+// ErrRepositoryNotFound means the remote reported the repository doesn't
+// exist, which for a private repo an unauthenticated caller can't
+// distinguish from ErrAuthenticationRequired at the transport level, so both
+// map from transport.ErrRepositoryNotFound.
+var ErrRepositoryNotFound = errors.New("gitops: repository not found")
+
+// ErrReferenceNotFound means the requested branch/tag/commit doesn't exist
+// in the repository, wrapping go-git's plumbing.ErrReferenceNotFound.
+var ErrReferenceNotFound = errors.New("gitops: reference not found")
+
+// mapError translates go-git's sentinel errors into this package's own, so
+// callers (e.g. analyzeRepository) can distinguish "bad credentials"/"repo
+// doesn't exist" (401/404) from every other failure (500) without importing
+// go-git's transport/plumbing packages themselves.
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, transport.ErrAuthenticationRequired):
+		return fmt.Errorf("%w: %v", ErrAuthenticationRequired, err)
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return fmt.Errorf("%w: %v", ErrRepositoryNotFound, err)
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		return fmt.Errorf("%w: %v", ErrReferenceNotFound, err)
+	default:
+		return err
+	}
+}
+
+// BasicAuth builds credentials for an HTTPS remote from a username/password
+// (or username/personal-access-token) pair.
+func BasicAuth(username, password string) transport.AuthMethod {
+	return &githttp.BasicAuth{Username: username, Password: password}
+}
+
+// TokenAuth builds credentials for an HTTPS remote from a bearer token
+// (GitHub App installation token or OAuth token), using the
+// "x-access-token" username GitHub's REST and Git-over-HTTPS APIs both
+// expect, matching the same convention
+// apps/api-gateway/auth_github.go's InjectCredential-equivalent clone URL
+// form uses.
+func TokenAuth(token string) transport.AuthMethod {
+	return &githttp.BasicAuth{Username: "x-access-token", Password: token}
+}
+
+// SSHKeyAuth builds credentials for an SSH remote from a PEM-encoded private
+// key, optionally passphrase-protected.
+func SSHKeyAuth(user string, pemBytes []byte, passphrase string) (transport.AuthMethod, error) {
+	auth, err := gitssh.NewPublicKeys(user, pemBytes, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+	return auth, nil
+}
+
+// CloneOpts configures Clone.
+type CloneOpts struct {
+	// URL is the repository's clone URL (https://, ssh://, or git@ form).
+	URL string
+	// Depth limits history to the most recent N commits; 0 clones full
+	// history.
+	Depth int
+	// SingleBranch clones only ReferenceName (or the remote's default
+	// branch if ReferenceName is empty) instead of every branch.
+	SingleBranch bool
+	// ReferenceName checks out this branch after cloning; empty means the
+	// remote's default branch. The name is always resolved as a branch
+	// (refs/heads/<name>), so a tag name is not found here.
+	ReferenceName string
+	// Auth supplies transport credentials; nil means an unauthenticated
+	// clone, which only succeeds against public repositories.
+	Auth transport.AuthMethod
+	// Progress, if set, receives go-git's human-readable clone progress
+	// output; analyzeRepository's worker can feed this into updateJob's
+	// progress reporting the same way it already streams job logs.
+	Progress io.Writer
+}
+
+// Repo wraps a cloned repository for Checkout/ListBranches/ResolveCommit.
+type Repo struct {
+	repo *git.Repository
+	path string
+}
+
+// Clone clones opts.URL into dir (which must not already exist or must be
+// empty) and returns a Repo positioned at opts.ReferenceName (or the
+// remote's default branch).
+func Clone(ctx context.Context, dir string, opts CloneOpts) (*Repo, error) {
+	cloneOpts := &git.CloneOptions{
+		URL:          opts.URL,
+		Auth:         opts.Auth,
+		Depth:        opts.Depth,
+		SingleBranch: opts.SingleBranch,
+		Progress:     opts.Progress,
+	}
+	if opts.ReferenceName != "" {
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(opts.ReferenceName)
+	}
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, cloneOpts)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &Repo{repo: repo, path: dir}, nil
+}
+
+// Checkout switches the working tree to ref, which may be a branch name, a
+// tag name, or a commit hash.
+func (r *Repo) Checkout(ref string) error {
+	hash, err := r.resolve(ref)
+	if err != nil {
+		return err
+	}
+
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// ListBranches returns every local branch name.
+func (r *Repo) ListBranches() ([]string, error) {
+	iter, err := r.repo.Branches()
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer iter.Close()
+
+	var branches []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// Commit is the subset of commit metadata callers (e.g. commit-status
+// posting, commit_history recording) need.
+type Commit struct {
+	Hash    string
+	Author  string
+	Message string
+	When    time.Time
+}
+
+// ResolveCommit resolves ref (branch, tag, or hash) to its commit metadata.
+func (r *Repo) ResolveCommit(ref string) (Commit, error) {
+	hash, err := r.resolve(ref)
+	if err != nil {
+		return Commit{}, err
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return Commit{}, mapError(err)
+	}
+
+	return Commit{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Message: commit.Message,
+		When:    commit.Author.When,
+	}, nil
+}
+
+// resolve turns a branch/tag/commit-hash string into a concrete hash.
+func (r *Repo) resolve(ref string) (*plumbing.Hash, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return hash, nil
+}
+
+// Path returns the working tree directory Clone wrote to.
+func (r *Repo) Path() string {
+	return r.path
+}
+
+// HTTPStatusForError maps an error returned by this package to the HTTP
+// status analyzeRepository-style handlers should respond with, so auth and
+// missing-ref failures surface as 401/404 instead of a generic 500.
+func HTTPStatusForError(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrAuthenticationRequired):
+		return 401
+	case errors.Is(err, ErrRepositoryNotFound), errors.Is(err, ErrReferenceNotFound):
+		return 404
+	default:
+		return 500
+	}
+}
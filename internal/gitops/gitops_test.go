@@ -0,0 +1,82 @@
+package gitops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestMapError_WrapsAuthenticationFailures(t *testing.T) {
+	got := mapError(transport.ErrAuthenticationRequired)
+	if !errors.Is(got, ErrAuthenticationRequired) {
+		t.Fatalf("expected %v to map to ErrAuthenticationRequired, got %v", transport.ErrAuthenticationRequired, got)
+	}
+}
+
+func TestMapError_WrapsRepositoryNotFound(t *testing.T) {
+	got := mapError(transport.ErrRepositoryNotFound)
+	if !errors.Is(got, ErrRepositoryNotFound) {
+		t.Fatalf("expected ErrRepositoryNotFound, got %v", got)
+	}
+}
+
+func TestMapError_WrapsReferenceNotFound(t *testing.T) {
+	got := mapError(plumbing.ErrReferenceNotFound)
+	if !errors.Is(got, ErrReferenceNotFound) {
+		t.Fatalf("expected ErrReferenceNotFound, got %v", got)
+	}
+}
+
+func TestMapError_PassesThroughUnrecognizedErrors(t *testing.T) {
+	other := errors.New("disk full")
+	if got := mapError(other); got != other {
+		t.Fatalf("expected unrecognized errors to pass through unchanged, got %v", got)
+	}
+}
+
+func TestMapError_NilIsNil(t *testing.T) {
+	if mapError(nil) != nil {
+		t.Fatal("expected a nil error to map to nil")
+	}
+}
+
+func TestHTTPStatusForError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{mapError(transport.ErrAuthenticationRequired), 401},
+		{mapError(transport.ErrRepositoryNotFound), 404},
+		{mapError(plumbing.ErrReferenceNotFound), 404},
+		{errors.New("disk full"), 500},
+	}
+	for _, c := range cases {
+		if got := HTTPStatusForError(c.err); got != c.want {
+			t.Fatalf("HTTPStatusForError(%v) = %d, want %d", c.err, got, c.want)
+		}
+	}
+}
+
+func TestTokenAuth_UsesXAccessTokenUsername(t *testing.T) {
+	auth, ok := TokenAuth("tok-123").(*githttp.BasicAuth)
+	if !ok {
+		t.Fatalf("expected *http.BasicAuth, got %T", TokenAuth("tok-123"))
+	}
+	if auth.Username != "x-access-token" || auth.Password != "tok-123" {
+		t.Fatalf("unexpected auth: %+v", auth)
+	}
+}
+
+func TestBasicAuth_UsesSuppliedCredentials(t *testing.T) {
+	auth, ok := BasicAuth("alice", "hunter2").(*githttp.BasicAuth)
+	if !ok {
+		t.Fatalf("expected *http.BasicAuth, got %T", BasicAuth("alice", "hunter2"))
+	}
+	if auth.Username != "alice" || auth.Password != "hunter2" {
+		t.Fatalf("unexpected auth: %+v", auth)
+	}
+}
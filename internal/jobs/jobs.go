@@ -0,0 +1,213 @@
+// Package jobs tracks webhook-driven analysis jobs per repo+branch ref,
+// coalescing deliveries that arrive while a ref's job is still running and
+// dropping near-duplicate deliveries (e.g. a GitHub webhook retry) shortly
+// after a ref's job completes.
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// RefID identifies one repo+branch push stream as "{repoURL}#{branch}".
+type RefID string
+
+// NewRefID builds the RefID for a repo URL and branch.
+func NewRefID(repoURL, branch string) RefID {
+	return RefID(repoURL + "#" + branch)
+}
+
+// Active describes a job the Tracker believes is currently running for a ref.
+type Active struct {
+	RefID     RefID
+	RepoURL   string
+	Branch    string
+	JobID     string
+	StartedAt time.Time
+}
+
+// Pending describes deliveries merged behind a ref's Active job, waiting for
+// it to finish before they're drained into a fresh job.
+type Pending struct {
+	RefID        RefID
+	RepoURL      string
+	Branch       string
+	Trigger      string
+	Before       string
+	After        string
+	ChangedFiles []string
+	RemovedFiles []string
+	MergedCount  int
+	StartedAt    time.Time
+}
+
+// Recent records a ref's last completed job so a near-duplicate delivery
+// arriving within the Tracker's TTL is dropped instead of re-queued.
+type Recent struct {
+	RefID       RefID
+	RepoURL     string
+	Branch      string
+	JobID       string
+	CompletedAt time.Time
+}
+
+// Tracker holds the Pending/Active/Recent state for webhook-driven analysis
+// jobs, keyed by RefID: an idle ref queues immediately, a ref with a running
+// job merges into its Pending slot, and a ref that just completed is held in
+// Recents for ttl so retried webhook deliveries are dropped rather than
+// re-queued.
+type Tracker struct {
+	mu      sync.Mutex
+	actives map[RefID]*Active
+	pending map[RefID]*Pending
+	recents map[RefID]*Recent
+	byJobID map[string]RefID
+	ttl     time.Duration
+}
+
+// NewTracker builds an empty Tracker whose Recents entries expire after ttl.
+func NewTracker(ttl time.Duration) *Tracker {
+	return &Tracker{
+		actives: make(map[RefID]*Active),
+		pending: make(map[RefID]*Pending),
+		recents: make(map[RefID]*Recent),
+		byJobID: make(map[string]RefID),
+		ttl:     ttl,
+	}
+}
+
+// Submit registers a webhook delivery for repoURL/branch. If the ref was
+// completed within the Recents TTL, dropped is true and the caller should
+// ignore the delivery entirely. Otherwise, if the ref has no job currently
+// active, shouldCreate is true and the caller should create a job and call
+// Activate with the result. If a job is already active for the ref, the
+// delivery is merged into its Pending slot and shouldCreate is false.
+func (t *Tracker) Submit(repoURL, branch, trigger, before, after string, changedFiles, removedFiles []string) (ref RefID, shouldCreate bool, dropped bool) {
+	ref = NewRefID(repoURL, branch)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if recent, ok := t.recents[ref]; ok {
+		if time.Since(recent.CompletedAt) < t.ttl {
+			return ref, false, true
+		}
+		delete(t.recents, ref)
+	}
+
+	if _, active := t.actives[ref]; active {
+		p, ok := t.pending[ref]
+		if !ok {
+			p = &Pending{RefID: ref, RepoURL: repoURL, Branch: branch, StartedAt: time.Now().UTC()}
+			t.pending[ref] = p
+		}
+		p.Trigger = trigger
+		if p.Before == "" {
+			p.Before = before
+		}
+		p.After = after
+		p.ChangedFiles = unionFiles(p.ChangedFiles, changedFiles)
+		p.RemovedFiles = unionFiles(p.RemovedFiles, removedFiles)
+		p.MergedCount++
+		return ref, false, false
+	}
+
+	return ref, true, false
+}
+
+// Activate records jobID as the running job for ref, called by the caller
+// right after it creates a job, whether fresh from Submit or promoted from a
+// drained Pending slot via Complete.
+func (t *Tracker) Activate(ref RefID, repoURL, branch, jobID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.actives[ref] = &Active{RefID: ref, RepoURL: repoURL, Branch: branch, JobID: jobID, StartedAt: time.Now().UTC()}
+	t.byJobID[jobID] = ref
+}
+
+// Complete marks jobID finished, moving its ref into Recents and returning
+// the ref's drained Pending slot, if any, so the caller can create a
+// follow-up job and call Activate again.
+func (t *Tracker) Complete(jobID string) *Pending {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ref, ok := t.byJobID[jobID]
+	if !ok {
+		return nil
+	}
+	active := t.actives[ref]
+	delete(t.byJobID, jobID)
+	delete(t.actives, ref)
+
+	recent := &Recent{RefID: ref, JobID: jobID, CompletedAt: time.Now().UTC()}
+	if active != nil {
+		recent.RepoURL = active.RepoURL
+		recent.Branch = active.Branch
+	}
+	t.recents[ref] = recent
+
+	pending := t.pending[ref]
+	delete(t.pending, ref)
+	return pending
+}
+
+// Actives returns a snapshot of every ref with a job currently running.
+func (t *Tracker) Actives() []*Active {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]*Active, 0, len(t.actives))
+	for _, a := range t.actives {
+		entry := *a
+		out = append(out, &entry)
+	}
+	return out
+}
+
+// Pendings returns a snapshot of every ref with deliveries merged behind a
+// still-running job.
+func (t *Tracker) Pendings() []*Pending {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]*Pending, 0, len(t.pending))
+	for _, p := range t.pending {
+		entry := *p
+		out = append(out, &entry)
+	}
+	return out
+}
+
+// Recents returns a snapshot of every ref still within the dedup TTL.
+func (t *Tracker) Recents() []*Recent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]*Recent, 0, len(t.recents))
+	for _, r := range t.recents {
+		entry := *r
+		out = append(out, &entry)
+	}
+	return out
+}
+
+// unionFiles merges incoming into existing, de-duplicating and preserving
+// first-seen order.
+func unionFiles(existing, incoming []string) []string {
+	if len(incoming) == 0 {
+		return existing
+	}
+	seen := make(map[string]bool, len(existing)+len(incoming))
+	out := make([]string, 0, len(existing)+len(incoming))
+	for _, f := range existing {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	for _, f := range incoming {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmit_IdleRefShouldCreate(t *testing.T) {
+	tr := NewTracker(time.Minute)
+
+	ref, shouldCreate, dropped := tr.Submit("https://github.com/o/r", "main", "push", "a", "b", []string{"x.go"}, nil)
+	if !shouldCreate || dropped {
+		t.Fatalf("expected shouldCreate=true, dropped=false, got shouldCreate=%v dropped=%v", shouldCreate, dropped)
+	}
+	if ref != NewRefID("https://github.com/o/r", "main") {
+		t.Fatalf("unexpected ref: %v", ref)
+	}
+}
+
+func TestSubmit_ActiveRefMergesIntoPending(t *testing.T) {
+	tr := NewTracker(time.Minute)
+
+	ref, _, _ := tr.Submit("https://github.com/o/r", "main", "push", "a", "b", []string{"x.go"}, nil)
+	tr.Activate(ref, "https://github.com/o/r", "main", "job-1")
+
+	_, shouldCreate, dropped := tr.Submit("https://github.com/o/r", "main", "push", "b", "c", []string{"y.go"}, nil)
+	if shouldCreate || dropped {
+		t.Fatalf("expected the second submit to merge, got shouldCreate=%v dropped=%v", shouldCreate, dropped)
+	}
+
+	pendings := tr.Pendings()
+	if len(pendings) != 1 || len(pendings[0].ChangedFiles) != 1 || pendings[0].MergedCount != 1 {
+		t.Fatalf("expected one merged pending entry, got %+v", pendings)
+	}
+}
+
+func TestComplete_DrainsPendingAndMarksRecent(t *testing.T) {
+	tr := NewTracker(time.Minute)
+
+	ref, _, _ := tr.Submit("https://github.com/o/r", "main", "push", "a", "b", nil, nil)
+	tr.Activate(ref, "https://github.com/o/r", "main", "job-1")
+	tr.Submit("https://github.com/o/r", "main", "push", "b", "c", []string{"y.go"}, nil)
+
+	pending := tr.Complete("job-1")
+	if pending == nil || pending.RefID != ref {
+		t.Fatalf("expected the merged pending slot to be drained, got %+v", pending)
+	}
+	if len(tr.Actives()) != 0 {
+		t.Fatalf("expected no actives left after Complete")
+	}
+	if len(tr.Recents()) != 1 {
+		t.Fatalf("expected the completed ref to be recorded as recent")
+	}
+}
+
+func TestSubmit_DropsNearDuplicateWithinTTL(t *testing.T) {
+	tr := NewTracker(time.Minute)
+
+	ref, _, _ := tr.Submit("https://github.com/o/r", "main", "push", "a", "b", nil, nil)
+	tr.Activate(ref, "https://github.com/o/r", "main", "job-1")
+	tr.Complete("job-1")
+
+	_, shouldCreate, dropped := tr.Submit("https://github.com/o/r", "main", "push", "b", "c", nil, nil)
+	if shouldCreate || !dropped {
+		t.Fatalf("expected the retried delivery to be dropped, got shouldCreate=%v dropped=%v", shouldCreate, dropped)
+	}
+}
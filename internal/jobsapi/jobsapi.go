@@ -0,0 +1,29 @@
+// Package jobsapi holds the request/response schema for the jobs API that
+// needs to be shared outside apps/api-gateway (e.g. by cmd/jobs-loadtest),
+// so that schema drift between the gateway and its tooling breaks the build
+// instead of silently diverging.
+package jobsapi
+
+import "time"
+
+// JobUpdateRequest represents the request body of PATCH /api/v1/jobs/:id.
+type JobUpdateRequest struct {
+	Status        *string                `json:"status,omitempty"`
+	Progress      *int                   `json:"progress,omitempty"`
+	ResultSummary map[string]interface{} `json:"result_summary,omitempty"`
+	Error         *string                `json:"error,omitempty"`
+	// Checkpoint is opaque, worker-defined state persisted alongside
+	// progress so a resumed job can pick up where a crashed worker left off.
+	Checkpoint map[string]interface{} `json:"checkpoint,omitempty"`
+	// Resumable marks whether a FAILED job may later be resumed via
+	// POST /api/v1/jobs/:id/resume instead of being a dead end.
+	Resumable *bool `json:"resumable,omitempty"`
+}
+
+// JobUpdateResponse represents the response after updating a job.
+type JobUpdateResponse struct {
+	JobID     string    `json:"job_id"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
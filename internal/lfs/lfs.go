@@ -0,0 +1,328 @@
+// Package lfs detects Git LFS pointer files left behind by a plain clone and
+// fetches their real content via the LFS Batch API, so static analysis does
+// not silently run against 130-byte pointer stubs instead of the binary
+// assets they reference.
+package lfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pointerHeader is the first line of every Git LFS pointer file, per
+// https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+const pointerHeader = "version https://git-lfs.github.com/spec/v1"
+
+// maxPointerFileSize bounds how much of a candidate file ParsePointer reads
+// before giving up on it; real pointer files are ~130 bytes, so anything
+// past a few KB is real content, not a pointer.
+const maxPointerFileSize = 4096
+
+// Pointer is a parsed Git LFS pointer file: the OID (content hash) and size
+// of the real object it stands in for.
+type Pointer struct {
+	OID  string
+	Size int64
+}
+
+// ParsePointer parses data as a Git LFS pointer file. ok is false if data
+// doesn't start with the LFS pointer header, in which case the file is
+// ordinary content and should be left alone.
+func ParsePointer(data []byte) (p Pointer, ok bool) {
+	if len(data) > maxPointerFileSize || !bytes.HasPrefix(data, []byte(pointerHeader)) {
+		return Pointer{}, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "oid sha256:"):
+			p.OID = strings.TrimPrefix(line, "oid sha256:")
+		case strings.HasPrefix(line, "size "):
+			size, err := strconv.ParseInt(strings.TrimPrefix(line, "size "), 10, 64)
+			if err != nil {
+				return Pointer{}, false
+			}
+			p.Size = size
+		}
+	}
+	if p.OID == "" || p.Size == 0 {
+		return Pointer{}, false
+	}
+	return p, true
+}
+
+// PointerFile is a pointer found while scanning a working tree.
+type PointerFile struct {
+	Path    string // absolute path on disk
+	Pointer Pointer
+}
+
+// ScanWorkingTree walks root (a repo's checked-out working tree, skipping
+// .git) looking for LFS pointer files.
+func ScanWorkingTree(root string) ([]PointerFile, error) {
+	var found []PointerFile
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Size() > maxPointerFileSize {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if pointer, ok := ParsePointer(data); ok {
+			found = append(found, PointerFile{Path: path, Pointer: pointer})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// BatchAction is one object's resolved download instructions from the Batch
+// API response's objects[].actions.download.
+type BatchAction struct {
+	OID    string
+	Size   int64
+	Href   string
+	Header map[string]string
+}
+
+// batchRequestObject is one entry in a Batch API request's "objects" array.
+type batchRequestObject struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// batchResponseObject is one entry in a Batch API response's "objects"
+// array; Actions is nil and Error is set when the server couldn't resolve
+// that object.
+type batchResponseObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Client talks to one remote's LFS Batch API and downloads the objects it
+// resolves.
+type Client struct {
+	// RemoteURL is the repo's clone URL; Batch POSTs to RemoteURL + "/info/lfs/objects/batch".
+	RemoteURL string
+	// Token, when set, is sent as "Authorization: Bearer <Token>" on the
+	// Batch request, so a linked GitHub OAuth token (see
+	// apps/api-gateway/auth_github.go) can also authorize private LFS
+	// objects. Download sends only the headers the Batch response supplies.
+	Token string
+	// HTTPClient defaults to a 30s-timeout client if nil.
+	HTTPClient *http.Client
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Batch resolves download instructions for pointers via the LFS Batch API.
+func (c *Client) Batch(ctx context.Context, pointers []Pointer) ([]BatchAction, error) {
+	objects := make([]batchRequestObject, len(pointers))
+	for i, p := range pointers {
+		objects[i] = batchRequestObject{OID: p.OID, Size: p.Size}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"operation": "download",
+		"transfers": []string{"basic"},
+		"objects":   objects,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(c.RemoteURL, "/") + "/info/lfs/objects/batch"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+	req.Header.Set("Accept", "application/vnd.git-lfs+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("lfs batch request to %s returned status %d", url, resp.StatusCode)
+	}
+
+	var parsed struct {
+		Objects []batchResponseObject `json:"objects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode lfs batch response: %w", err)
+	}
+
+	actions := make([]BatchAction, 0, len(parsed.Objects))
+	for _, obj := range parsed.Objects {
+		if obj.Error != nil {
+			return nil, fmt.Errorf("lfs batch: object %s: %s (code %d)", obj.OID, obj.Error.Message, obj.Error.Code)
+		}
+		if obj.Actions.Download == nil {
+			return nil, fmt.Errorf("lfs batch: object %s carried no download action", obj.OID)
+		}
+		actions = append(actions, BatchAction{
+			OID:    obj.OID,
+			Size:   obj.Size,
+			Href:   obj.Actions.Download.Href,
+			Header: obj.Actions.Download.Header,
+		})
+	}
+	return actions, nil
+}
+
+// ObjectPath returns the on-disk path an object's content is stored at
+// beneath a repo's .git directory, matching git-lfs's own
+// ".git/lfs/objects/<oid[0:2]>/<oid[2:4]>/<oid>" sharding.
+func ObjectPath(gitDir, oid string) string {
+	if len(oid) < 4 {
+		return filepath.Join(gitDir, "lfs", "objects", oid)
+	}
+	return filepath.Join(gitDir, "lfs", "objects", oid[0:2], oid[2:4], oid)
+}
+
+// Download streams one resolved action's content to ObjectPath(gitDir,
+// action.OID), creating parent directories as needed, and returns the path
+// it wrote to.
+func (c *Client) Download(ctx context.Context, gitDir string, action BatchAction) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, action.Href, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("lfs download of %s returned status %d", action.OID, resp.StatusCode)
+	}
+
+	dest := ObjectPath(gitDir, action.OID)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		os.Remove(dest)
+		return "", fmt.Errorf("failed to write lfs object %s: %w", action.OID, err)
+	}
+	return dest, nil
+}
+
+// Smudge downloads every pointer file found in the working tree rooted at
+// workTree (via ScanWorkingTree) and overwrites each pointer file in place
+// with its real content, the same end state "git lfs smudge" leaves behind
+// during a normal checkout.
+func (c *Client) Smudge(ctx context.Context, workTree, gitDir string) error {
+	pointerFiles, err := ScanWorkingTree(workTree)
+	if err != nil {
+		return fmt.Errorf("failed to scan working tree for lfs pointers: %w", err)
+	}
+	if len(pointerFiles) == 0 {
+		return nil
+	}
+
+	pointers := make([]Pointer, len(pointerFiles))
+	for i, pf := range pointerFiles {
+		pointers[i] = pf.Pointer
+	}
+
+	actions, err := c.Batch(ctx, pointers)
+	if err != nil {
+		return err
+	}
+	actionsByOID := make(map[string]BatchAction, len(actions))
+	for _, a := range actions {
+		actionsByOID[a.OID] = a
+	}
+
+	for _, pf := range pointerFiles {
+		action, ok := actionsByOID[pf.Pointer.OID]
+		if !ok {
+			return fmt.Errorf("lfs batch response omitted object %s (referenced by %s)", pf.Pointer.OID, pf.Path)
+		}
+
+		objectPath, err := c.Download(ctx, gitDir, action)
+		if err != nil {
+			return fmt.Errorf("failed to download lfs object for %s: %w", pf.Path, err)
+		}
+		if err := replaceWithObjectContent(pf.Path, objectPath); err != nil {
+			return fmt.Errorf("failed to smudge %s: %w", pf.Path, err)
+		}
+	}
+	return nil
+}
+
+// replaceWithObjectContent overwrites pointerPath with the content already
+// downloaded to objectPath.
+func replaceWithObjectContent(pointerPath, objectPath string) error {
+	data, err := os.ReadFile(objectPath)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(pointerPath)
+	var mode os.FileMode = 0o644
+	if err == nil {
+		mode = info.Mode()
+	}
+	return os.WriteFile(pointerPath, data, mode)
+}
@@ -0,0 +1,184 @@
+package lfs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const testOID = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2fa"
+
+func TestParsePointer_ParsesValidPointerFile(t *testing.T) {
+	data := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:" + testOID + "\nsize 12345\n")
+	p, ok := ParsePointer(data)
+	if !ok {
+		t.Fatal("expected a valid pointer to parse")
+	}
+	if p.OID != testOID || p.Size != 12345 {
+		t.Fatalf("unexpected pointer: %+v", p)
+	}
+}
+
+func TestParsePointer_RejectsOrdinaryContent(t *testing.T) {
+	if _, ok := ParsePointer([]byte("package main\n\nfunc main() {}\n")); ok {
+		t.Fatal("expected ordinary file content to not parse as a pointer")
+	}
+}
+
+func TestParsePointer_RejectsOversizedInput(t *testing.T) {
+	huge := make([]byte, maxPointerFileSize+1)
+	copy(huge, pointerHeader)
+	if _, ok := ParsePointer(huge); ok {
+		t.Fatal("expected oversized input to be rejected")
+	}
+}
+
+func TestScanWorkingTree_FindsPointersAndSkipsGitDir(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "asset.bin"), "version https://git-lfs.github.com/spec/v1\noid sha256:"+testOID+"\nsize 99\n")
+	mustWriteFile(t, filepath.Join(root, "README.md"), "just a normal file")
+	mustWriteFile(t, filepath.Join(root, ".git", "lfs", "objects", "fake"), "version https://git-lfs.github.com/spec/v1\noid sha256:deadbeef\nsize 1\n")
+
+	found, err := ScanWorkingTree(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected 1 pointer file, got %d: %+v", len(found), found)
+	}
+	if found[0].Pointer.OID != testOID {
+		t.Fatalf("unexpected oid: %s", found[0].Pointer.OID)
+	}
+}
+
+func TestClient_Batch_ParsesDownloadActions(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info/lfs/objects/batch" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok-123" {
+			t.Fatalf("expected Authorization header to carry the token, got %q", got)
+		}
+
+		var reqBody struct {
+			Objects []batchRequestObject `json:"objects"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+		if len(reqBody.Objects) != 1 || reqBody.Objects[0].OID != testOID {
+			t.Fatalf("unexpected batch request body: %+v", reqBody)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"objects": []map[string]interface{}{
+				{
+					"oid":  testOID,
+					"size": 99,
+					"actions": map[string]interface{}{
+						"download": map[string]interface{}{
+							"href":   server.URL + "/storage/" + testOID,
+							"header": map[string]string{"X-Custom": "yes"},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{RemoteURL: server.URL, Token: "tok-123"}
+	actions, err := client.Batch(context.Background(), []Pointer{{OID: testOID, Size: 99}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Href == "" {
+		t.Fatalf("unexpected actions: %+v", actions)
+	}
+}
+
+func TestClient_Batch_SurfacesPerObjectErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"objects": []map[string]interface{}{
+				{
+					"oid":  testOID,
+					"size": 99,
+					"error": map[string]interface{}{
+						"code":    404,
+						"message": "object not found",
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &Client{RemoteURL: server.URL}
+	_, err := client.Batch(context.Background(), []Pointer{{OID: testOID, Size: 99}})
+	if err == nil {
+		t.Fatal("expected an error for a per-object batch failure")
+	}
+}
+
+func TestClient_Smudge_DownloadsAndReplacesPointerFiles(t *testing.T) {
+	const content = "binary-asset-content"
+
+	var storageServer *httptest.Server
+	batchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"objects": []map[string]interface{}{
+				{
+					"oid":  testOID,
+					"size": len(content),
+					"actions": map[string]interface{}{
+						"download": map[string]interface{}{"href": storageServer.URL},
+					},
+				},
+			},
+		})
+	}))
+	defer batchServer.Close()
+
+	storageServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer storageServer.Close()
+
+	workTree := t.TempDir()
+	gitDir := filepath.Join(workTree, ".git")
+	pointerPath := filepath.Join(workTree, "asset.bin")
+	mustWriteFile(t, pointerPath, "version https://git-lfs.github.com/spec/v1\noid sha256:"+testOID+"\nsize "+strconv.Itoa(len(content))+"\n")
+
+	client := &Client{RemoteURL: batchServer.URL}
+	if err := client.Smudge(context.Background(), workTree, gitDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(pointerPath)
+	if err != nil {
+		t.Fatalf("unexpected error reading smudged file: %v", err)
+	}
+	if string(got) != content {
+		t.Fatalf("expected pointer file to be replaced with real content, got %q", got)
+	}
+
+	objectPath := ObjectPath(gitDir, testOID)
+	if _, err := os.Stat(objectPath); err != nil {
+		t.Fatalf("expected lfs object to be cached at %s: %v", objectPath, err)
+	}
+}
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create dir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,146 @@
+// Package retry implements a reusable retry policy with capped exponential
+// backoff and full jitter, so callers don't each hand-roll their own
+// unbounded 1<<attempt wait (which also synchronizes retries across
+// replicas into a thundering herd against whatever they're calling).
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// defaultMultiplier and defaultJitterFactor are used when a Policy leaves
+// Multiplier/JitterFactor at their zero value.
+const (
+	defaultMultiplier   = 2.0
+	defaultJitterFactor = 0.2
+)
+
+// Policy configures how Do retries a failing operation.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// InitialDelay is the backoff before the second attempt.
+	InitialDelay time.Duration
+	// MaxDelay caps the computed backoff regardless of attempt count.
+	MaxDelay time.Duration
+	// Multiplier scales the delay each attempt; defaults to 2 if zero.
+	Multiplier float64
+	// JitterFactor, from 0 to 1, sets the fixed part of each sleep: Do
+	// sleeps (1-JitterFactor)*delay plus a random 0..delay, so 1 gives a
+	// sleep anywhere in 0..delay. Defaults to 0.2 if zero.
+	JitterFactor float64
+	// RetryableErr reports whether err should be retried. It is consulted
+	// only when RetryableStatus does not decide: err is not a *StatusError,
+	// or RetryableStatus is empty. Nil means every such error is retryable.
+	RetryableErr func(error) bool
+	// RetryableStatus lists HTTP status codes worth retrying, for operations
+	// that fail with a *StatusError instead of a plain error.
+	RetryableStatus []int
+}
+
+// StatusError lets an operation report an HTTP status code to Policy's
+// RetryableStatus check without Do needing to know about net/http.
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// Error wraps the last error seen once every attempt has been exhausted,
+// recording how many attempts were made.
+type Error struct {
+	Attempts int
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("retry: failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+func (e *Error) Unwrap() error { return e.Err }
+
+// Do calls op, retrying on failure according to policy until it succeeds,
+// ctx is cancelled, a non-retryable error is returned, or MaxAttempts is
+// exhausted. On exhaustion the returned error is an *Error wrapping op's
+// last error.
+func Do(ctx context.Context, policy Policy, op func(ctx context.Context) error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		lastErr = op(ctx)
+		if lastErr == nil {
+			return nil
+		}
+		if !isRetryable(policy, lastErr) {
+			return lastErr
+		}
+		if attempt == maxAttempts {
+			break
+		}
+
+		delay := backoffDelay(policy, attempt)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return &Error{Attempts: maxAttempts, Err: lastErr}
+}
+
+func isRetryable(policy Policy, err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && len(policy.RetryableStatus) > 0 {
+		for _, code := range policy.RetryableStatus {
+			if code == statusErr.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+	if policy.RetryableErr != nil {
+		return policy.RetryableErr(err)
+	}
+	return true
+}
+
+// backoffDelay computes delay = min(MaxDelay, InitialDelay*Multiplier^(attempt-1))
+// then applies full jitter: sleep = rand(0, delay) + (1-JitterFactor)*delay.
+func backoffDelay(policy Policy, attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier == 0 {
+		multiplier = defaultMultiplier
+	}
+	jitterFactor := policy.JitterFactor
+	if jitterFactor == 0 {
+		jitterFactor = defaultJitterFactor
+	}
+
+	delay := float64(policy.InitialDelay) * pow(multiplier, attempt-1)
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	sleep := rand.Float64()*delay + (1-jitterFactor)*delay
+	return time.Duration(sleep)
+}
+
+func pow(base float64, exp int) float64 {
+	result := 1.0
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDo_SucceedsWithoutRetryOnFirstAttempt(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 3}, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 3, InitialDelay: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDo_ExhaustsMaxAttemptsAndWrapsLastError(t *testing.T) {
+	wantErr := errors.New("still failing")
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 3, InitialDelay: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return wantErr
+	})
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	var retryErr *Error
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if retryErr.Attempts != 3 || !errors.Is(retryErr, wantErr) {
+		t.Fatalf("unexpected wrapped error: %+v", retryErr)
+	}
+}
+
+func TestDo_NonRetryableErrShortCircuits(t *testing.T) {
+	calls := 0
+	policy := Policy{
+		MaxAttempts:  5,
+		InitialDelay: time.Millisecond,
+		RetryableErr: func(err error) bool { return false },
+	}
+	err := Do(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return errors.New("permanent")
+	})
+	if calls != 1 {
+		t.Fatalf("expected 1 call before short-circuiting, got %d", calls)
+	}
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDo_RetryableStatusOnlyRetriesListedCodes(t *testing.T) {
+	calls := 0
+	policy := Policy{
+		MaxAttempts:     3,
+		InitialDelay:    time.Millisecond,
+		RetryableStatus: []int{503},
+	}
+	err := Do(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return &StatusError{StatusCode: 404, Err: errors.New("not found")}
+	})
+	if calls != 1 {
+		t.Fatalf("expected 1 call for a non-listed status, got %d", calls)
+	}
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDo_ContextCancelledMidSleepReturnsCtxErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+
+	err := Do(ctx, Policy{MaxAttempts: 5, InitialDelay: time.Hour}, func(ctx context.Context) error {
+		calls++
+		return errors.New("transient")
+	})
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call before the sleep was cancelled, got %d", calls)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestBackoffDelay_StaysWithinMaxDelayBound(t *testing.T) {
+	policy := Policy{InitialDelay: time.Second, MaxDelay: 2 * time.Second, Multiplier: 2, JitterFactor: 1}
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := backoffDelay(policy, attempt)
+		if delay > 2*2*time.Second {
+			t.Fatalf("attempt %d: delay %v exceeded the expected bound", attempt, delay)
+		}
+	}
+}
+
+func TestBackoffDelay_FullJitterCanReturnZero(t *testing.T) {
+	// With JitterFactor=1, sleep = rand(0, delay) + 0, so across many draws
+	// the distribution should cover values well below delay itself.
+	policy := Policy{InitialDelay: 100 * time.Millisecond, Multiplier: 1, JitterFactor: 1}
+	sawSmall := false
+	for i := 0; i < 200; i++ {
+		if backoffDelay(policy, 1) < 20*time.Millisecond {
+			sawSmall = true
+			break
+		}
+	}
+	if !sawSmall {
+		t.Fatal("expected full jitter to occasionally produce a small delay")
+	}
+}